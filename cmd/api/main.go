@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"golang_task_manager_folder_structure/internal/api"
 	"golang_task_manager_folder_structure/internal/config"
+	"golang_task_manager_folder_structure/internal/cron"
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
 	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/storage"
+	"golang_task_manager_folder_structure/internal/tracing"
 )
 
 func main() {
@@ -17,9 +27,40 @@ func main() {
 	}
 
 	// Setup logger
-	logger := logger.NewLogger(cfg.LogLevel)
+	logger, err := logger.NewLogger(logger.Options{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		Output:     cfg.LogOutput,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxBackups: cfg.LogMaxBackups,
+	})
+	if err != nil {
+		log.Fatalf("Failed to setup logger: %v", err)
+	}
 	logger.Info("Starting API server...")
 
+	// Anyone who knows the secret can forge login tokens, so there is no default
+	if cfg.JWTSecret == "" || cfg.JWTSecret == "your-secret-key" {
+		logger.Fatal("JWT_SECRET must be set to a random secret", nil)
+	}
+
+	// Setup tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: "task-manager-api",
+		Exporter:    cfg.TracingExporter,
+		Output:      cfg.TracingOutput,
+	})
+	if err != nil {
+		logger.Fatal("Failed to setup tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", err)
+		}
+	}()
+
 	// Setup database
 	db, err := repository.NewDatabase(cfg.DatabaseURL)
 	if err != nil {
@@ -28,14 +69,50 @@ func main() {
 	defer db.Close()
 
 	// Initialize repositories
-	taskRepo := repository.NewTaskRepository(db)
+	repos := repository.NewRepositories(db)
+
+	// Setup attachment storage
+	blobs, err := storage.NewBlobStore(cfg)
+	if err != nil {
+		logger.Fatal("Failed to setup attachment storage", err)
+	}
 
 	// Initialize services
-	services := api.NewServices(taskRepo, logger)
+	services := api.NewServices(cfg, repos, blobs, logger)
+
+	// Collect runtime, database pool and task metrics
+	registry := metrics.NewRegistry(db)
+	registry.MustRegister(metrics.NewTaskCollector(repos.Tasks))
+
+	// Run until interrupted or terminated
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Run the scheduled jobs in this process when configured to; they stop
+	// together with the server
+	var jobs sync.WaitGroup
+	if cfg.RunScheduler == "embedded" {
+		scheduler, err := cron.NewSchedulerFromConfig(cfg, repos, blobs, metrics.NewJobs(registry), logger)
+		if err != nil {
+			logger.Fatal("Failed to schedule jobs", err)
+		}
+
+		logger.Info("Running scheduled jobs in the API process")
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			scheduler.Start(ctx)
+		}()
+	}
 
 	// Setup and start server
-	server := api.NewServer(cfg, services, logger)
-	if err := server.Start(); err != nil {
-		logger.Fatal("Failed to start server", err)
+	server := api.NewServer(cfg, services, registry, logger)
+	err = server.Start(ctx)
+
+	// A failed server stops the embedded jobs just like a signal does
+	stop()
+	jobs.Wait()
+	if err != nil {
+		logger.Fatal("Server failed", err)
 	}
 }
@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"golang_task_manager_folder_structure/internal/config"
 	"golang_task_manager_folder_structure/internal/cron"
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
 	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/storage"
+	"golang_task_manager_folder_structure/internal/tracing"
 )
 
 func main() {
@@ -17,9 +27,35 @@ func main() {
 	}
 
 	// Setup logger
-	logger := logger.NewLogger(cfg.LogLevel)
+	logger, err := logger.NewLogger(logger.Options{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		Output:     cfg.LogOutput,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxBackups: cfg.LogMaxBackups,
+	})
+	if err != nil {
+		log.Fatalf("Failed to setup logger: %v", err)
+	}
 	logger.Info("Starting cron jobs...")
 
+	// Setup tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: "task-manager-cron",
+		Exporter:    cfg.TracingExporter,
+		Output:      cfg.TracingOutput,
+	})
+	if err != nil {
+		logger.Fatal("Failed to setup tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", err)
+		}
+	}()
+
 	// Setup database
 	db, err := repository.NewDatabase(cfg.DatabaseURL)
 	if err != nil {
@@ -28,9 +64,43 @@ func main() {
 	defer db.Close()
 
 	// Initialize repositories
-	taskRepo := repository.NewTaskRepository(db)
+	repos := repository.NewRepositories(db)
+
+	// Setup attachment storage
+	blobs, err := storage.NewBlobStore(cfg)
+	if err != nil {
+		logger.Fatal("Failed to setup attachment storage", err)
+	}
+
+	// Collect runtime, database pool and job metrics
+	registry := metrics.NewRegistry(db)
+
+	// Setup scheduler
+	scheduler, err := cron.NewSchedulerFromConfig(cfg, repos, blobs, metrics.NewJobs(registry), logger)
+	if err != nil {
+		logger.Fatal("Failed to schedule jobs", err)
+	}
+
+	// Serve the metrics on their own port
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler(registry))
+	metricsServer := &http.Server{
+		Addr:        fmt.Sprintf("%s:%d", cfg.ServerHost, cfg.MetricsPort),
+		Handler:     mux,
+		ReadTimeout: 15 * time.Second,
+	}
+	go func() {
+		logger.Info("Metrics server starting", "addr", metricsServer.Addr)
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Metrics server failed", err)
+		}
+	}()
+
+	// Run until interrupted or terminated
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Setup and start scheduler
-	scheduler := cron.NewScheduler(taskRepo, logger)
-	scheduler.Start()
+	scheduler.Start(ctx)
+	metricsServer.Close()
+	logger.Info("Cron jobs stopped")
 }
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// multipartOverhead is allowed on top of the attachment size for the
+// multipart framing and headers
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles HTTP requests for task attachments
+type AttachmentHandler struct {
+	service *services.AttachmentService
+	logger  *logger.Logger
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler
+func NewAttachmentHandler(service *services.AttachmentService, logger *logger.Logger) *AttachmentHandler {
+	return &AttachmentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the attachments of a task
+func (h *AttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := h.service.List(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get attachments")
+		return
+	}
+
+	respondJSON(w, attachments, http.StatusOK)
+}
+
+// Upload stores the "file" part of a multipart/form-data request. The file
+// is streamed to storage rather than buffered in memory.
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.MaxBytes()+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Expected a multipart/form-data request", http.StatusBadRequest)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			http.Error(w, "Missing file field", http.StatusBadRequest)
+			return
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Attachment is too large", http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, "Invalid multipart body", http.StatusBadRequest)
+			return
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.service.Upload(r.Context(), actor(r), taskID, part.FileName(), part)
+		part.Close()
+		switch {
+		case errors.Is(err, services.ErrAttachmentTooLarge), errors.As(err, &tooLarge):
+			http.Error(w, "Attachment is too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrUnsupportedFileType):
+			http.Error(w, "Unsupported attachment type", http.StatusUnsupportedMediaType)
+		case err != nil:
+			respondError(w, r, h.logger, err, "Failed to upload attachment")
+		default:
+			respondJSON(w, attachment, http.StatusCreated)
+		}
+		return
+	}
+}
+
+// Download streams an attachment. Range requests are supported.
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	id, err := intParam(r, "attachmentID")
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, blob, err := h.service.Open(r.Context(), actor(r), taskID, id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get attachment")
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, attachment.Filename, attachment.CreatedAt, blob)
+}
+
+// Delete removes an attachment
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	id, err := intParam(r, "attachmentID")
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), actor(r), taskID, id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete attachment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
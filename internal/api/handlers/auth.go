@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/api/middlewares"
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// AuthHandler handles registration and login requests
+type AuthHandler struct {
+	service *services.AuthService
+	logger  *logger.Logger
+}
+
+// RegisterRequest represents a registration request body
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginRequest represents a login request body
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginResponse holds the issued token and the authenticated user
+type LoginResponse struct {
+	Token string           `json:"token"`
+	User  *repository.User `json:"user"`
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(service *services.AuthService, logger *logger.Logger) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Register creates a new user account
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.Register(req.Username, req.Email, req.Password)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to register user")
+		return
+	}
+
+	respondJSON(w, user, http.StatusCreated)
+}
+
+// Login exchanges credentials for a token
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token, user, err := h.service.Login(req.Username, req.Password)
+	if err == services.ErrInvalidCredentials {
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		respondError(w, r, h.logger, err, "Failed to log in")
+		return
+	}
+
+	respondJSON(w, LoginResponse{Token: token, User: user}, http.StatusOK)
+}
+
+// Me returns the authenticated user
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, middlewares.UserFromContext(r.Context()), http.StatusOK)
+}
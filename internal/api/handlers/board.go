@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// BoardHandler handles HTTP requests for Kanban boards
+type BoardHandler struct {
+	service *services.BoardService
+	logger  *logger.Logger
+}
+
+// BoardRequest represents a board request body. Columns default to
+// To do, In progress and Done.
+type BoardRequest struct {
+	Name        string                   `json:"name"`
+	WorkspaceID *int                     `json:"workspace_id,omitempty"`
+	ProjectID   *int                     `json:"project_id,omitempty"`
+	Columns     []repository.BoardColumn `json:"columns,omitempty"`
+}
+
+// NewBoardHandler creates a new BoardHandler
+func NewBoardHandler(service *services.BoardService, logger *logger.Logger) *BoardHandler {
+	return &BoardHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the boards visible to the current user
+func (h *BoardHandler) List(w http.ResponseWriter, r *http.Request) {
+	boards, err := h.service.List(actor(r))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get boards")
+		return
+	}
+
+	respondJSON(w, boards, http.StatusOK)
+}
+
+// Create adds a new board
+func (h *BoardHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req BoardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	board, err := h.service.Create(actor(r), services.BoardInput{
+		Name:        req.Name,
+		WorkspaceID: req.WorkspaceID,
+		ProjectID:   req.ProjectID,
+		Columns:     req.Columns,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create board")
+		return
+	}
+
+	respondJSON(w, board, http.StatusCreated)
+}
+
+// Get returns a board with the tasks of each column
+func (h *BoardHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid board ID", http.StatusBadRequest)
+		return
+	}
+
+	board, err := h.service.Get(r.Context(), actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get board")
+		return
+	}
+
+	respondJSON(w, board, http.StatusOK)
+}
+
+// SaveColumns reconfigures the columns of a board
+func (h *BoardHandler) SaveColumns(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid board ID", http.StatusBadRequest)
+		return
+	}
+
+	var columns []repository.BoardColumn
+	if err := json.NewDecoder(r.Body).Decode(&columns); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	board, err := h.service.SaveColumns(actor(r), id, columns)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to save board columns")
+		return
+	}
+
+	respondJSON(w, board, http.StatusOK)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// ChecklistHandler handles HTTP requests for task checklists
+type ChecklistHandler struct {
+	service *services.ChecklistService
+	logger  *logger.Logger
+}
+
+// ChecklistItemRequest represents a checklist item request body
+type ChecklistItemRequest struct {
+	Text    string `json:"text"`
+	Checked *bool  `json:"checked,omitempty"`
+}
+
+// ChecklistOrderRequest lists the IDs of all checklist items in their new order
+type ChecklistOrderRequest struct {
+	ItemIDs []int `json:"item_ids"`
+}
+
+// NewChecklistHandler creates a new ChecklistHandler
+func NewChecklistHandler(service *services.ChecklistService, logger *logger.Logger) *ChecklistHandler {
+	return &ChecklistHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the checklist of a task
+func (h *ChecklistHandler) List(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.service.List(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get checklist")
+		return
+	}
+
+	respondJSON(w, items, http.StatusOK)
+}
+
+// Add appends an item to a task's checklist
+func (h *ChecklistHandler) Add(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.service.Add(r.Context(), actor(r), taskID, req.Text)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to add checklist item")
+		return
+	}
+
+	respondJSON(w, item, http.StatusCreated)
+}
+
+// Update changes the text or checked state of a checklist item
+func (h *ChecklistHandler) Update(w http.ResponseWriter, r *http.Request) {
+	taskID, itemID, ok := checklistParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.service.Update(r.Context(), actor(r), taskID, itemID, req.Text, req.Checked)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to update checklist item")
+		return
+	}
+
+	respondJSON(w, item, http.StatusOK)
+}
+
+// Toggle flips the checked state of a checklist item
+func (h *ChecklistHandler) Toggle(w http.ResponseWriter, r *http.Request) {
+	taskID, itemID, ok := checklistParams(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.service.Toggle(r.Context(), actor(r), taskID, itemID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to toggle checklist item")
+		return
+	}
+
+	respondJSON(w, item, http.StatusOK)
+}
+
+// Reorder changes the order of a task's checklist
+func (h *ChecklistHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ChecklistOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.service.Reorder(r.Context(), actor(r), taskID, req.ItemIDs)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to reorder checklist")
+		return
+	}
+
+	respondJSON(w, items, http.StatusOK)
+}
+
+// Delete removes a checklist item
+func (h *ChecklistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	taskID, itemID, ok := checklistParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), actor(r), taskID, itemID); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete checklist item")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checklistParams parses the task and item IDs, responding with 400 when invalid
+func checklistParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	itemID, err := intParam(r, "itemID")
+	if err != nil {
+		http.Error(w, "Invalid checklist item ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return taskID, itemID, true
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// CommentHandler handles HTTP requests for task comments
+type CommentHandler struct {
+	service *services.CommentService
+	logger  *logger.Logger
+}
+
+// CommentRequest represents a comment request body
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+// NewCommentHandler creates a new CommentHandler
+func NewCommentHandler(service *services.CommentService, logger *logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the comments of a task
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	comments, err := h.service.List(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get comments")
+		return
+	}
+
+	respondJSON(w, comments, http.StatusOK)
+}
+
+// Create posts a comment on a task
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.service.Create(r.Context(), actor(r), taskID, req.Body)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create comment")
+		return
+	}
+
+	respondJSON(w, comment, http.StatusCreated)
+}
+
+// Update edits a comment
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	id, err := intParam(r, "commentID")
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.service.Update(r.Context(), actor(r), taskID, id, req.Body)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to update comment")
+		return
+	}
+
+	respondJSON(w, comment, http.StatusOK)
+}
+
+// Delete removes a comment
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	id, err := intParam(r, "commentID")
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), actor(r), taskID, id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete comment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Activity returns the comments and changes of a task as one thread
+func (h *CommentHandler) Activity(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	activity, err := h.service.Activity(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get task activity")
+		return
+	}
+
+	respondJSON(w, activity, http.StatusOK)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// DigestHandler handles HTTP requests for digest email settings
+type DigestHandler struct {
+	service *services.DigestService
+	logger  *logger.Logger
+}
+
+// DigestRequest represents a digest settings request body
+type DigestRequest struct {
+	Frequency string `json:"frequency"`
+	Hour      *int   `json:"hour"`
+	Weekday   string `json:"weekday"`
+	Timezone  string `json:"timezone"`
+}
+
+// NewDigestHandler creates a new DigestHandler
+func NewDigestHandler(service *services.DigestService, logger *logger.Logger) *DigestHandler {
+	return &DigestHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Get returns the current user's digest settings
+func (h *DigestHandler) Get(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.Settings(actor(r))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get digest settings")
+		return
+	}
+
+	respondJSON(w, settings, http.StatusOK)
+}
+
+// Save changes when the current user receives digests
+func (h *DigestHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var req DigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.service.SaveSettings(actor(r), services.DigestInput{
+		Frequency: req.Frequency,
+		Hour:      req.Hour,
+		Weekday:   req.Weekday,
+		Timezone:  req.Timezone,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to save digest settings")
+		return
+	}
+
+	respondJSON(w, settings, http.StatusOK)
+}
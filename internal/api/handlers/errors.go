@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang_task_manager_folder_structure/internal/api/middlewares"
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// notFoundErrors are repository errors reported to clients as 404s
+var notFoundErrors = []error{
+	repository.ErrTaskNotFound,
+	repository.ErrUserNotFound,
+	repository.ErrWorkspaceNotFound,
+	repository.ErrMemberNotFound,
+	repository.ErrTokenNotFound,
+	repository.ErrCommentNotFound,
+	repository.ErrAttachmentNotFound,
+	repository.ErrProjectNotFound,
+	repository.ErrTimeEntryNotFound,
+	repository.ErrChecklistItemNotFound,
+	repository.ErrBoardNotFound,
+	repository.ErrBoardColumnNotFound,
+	repository.ErrTemplateNotFound,
+	repository.ErrReminderNotFound,
+	repository.ErrDigestSettingsNotFound,
+	repository.ErrJobNotFound,
+	repository.ErrQueueJobNotFound,
+}
+
+// respondError maps a service error to an HTTP response. Unexpected errors
+// are logged with the request-scoped logger and reported with the given message.
+func respondError(w http.ResponseWriter, r *http.Request, log *logger.Logger, err error, message string) {
+	var invalid *services.ValidationError
+	if errors.As(err, &invalid) {
+		http.Error(w, invalid.Message, http.StatusBadRequest)
+		return
+	}
+
+	var conflict *services.ConflictError
+	if errors.As(err, &conflict) {
+		http.Error(w, conflict.Message, http.StatusConflict)
+		return
+	}
+
+	for _, notFound := range notFoundErrors {
+		if errors.Is(err, notFound) {
+			http.Error(w, capitalize(err.Error()), http.StatusNotFound)
+			return
+		}
+	}
+
+	var forbidden *policy.ForbiddenError
+	if errors.As(err, &forbidden) {
+		http.Error(w, "Forbidden: "+forbidden.Reason, http.StatusForbidden)
+		return
+	}
+
+	middlewares.RequestLogger(r, log).Error(message, err)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// actor returns the policy actor of the authenticated user
+func actor(r *http.Request) policy.Actor {
+	return policy.User(middlewares.UserFromContext(r.Context()).ID)
+}
+
+// intParam parses a numeric URL parameter
+func intParam(r *http.Request, name string) (int, error) {
+	return strconv.Atoi(chi.URLParam(r, name))
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// JobHandler handles HTTP requests for scheduled jobs
+type JobHandler struct {
+	service *services.JobService
+	logger  *logger.Logger
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(service *services.JobService, logger *logger.Logger) *JobHandler {
+	return &JobHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the scheduled jobs with their next run and last result
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.service.List()
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to list jobs")
+		return
+	}
+
+	respondJSON(w, jobs, http.StatusOK)
+}
+
+// Runs returns the recent runs of a job
+func (h *JobHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.service.Runs(chi.URLParam(r, "name"))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to list job runs")
+		return
+	}
+
+	respondJSON(w, runs, http.StatusOK)
+}
+
+// Run asks the scheduler to run a job right away
+func (h *JobHandler) Run(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.Trigger(chi.URLParam(r, "name"))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to trigger job")
+		return
+	}
+
+	respondJSON(w, job, http.StatusAccepted)
+}
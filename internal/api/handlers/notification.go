@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/api/middlewares"
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// NotificationHandler handles HTTP requests for the notification inbox
+type NotificationHandler struct {
+	service *services.NotificationService
+	logger  *logger.Logger
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(service *services.NotificationService, logger *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the current user's notifications
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.UserFromContext(r.Context())
+
+	notifications, err := h.service.List(user.ID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get notifications")
+		return
+	}
+
+	respondJSON(w, notifications, http.StatusOK)
+}
+
+// MarkRead marks a notification as read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	user := middlewares.UserFromContext(r.Context())
+
+	if err := h.service.MarkRead(user.ID, id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to update notification")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// ProjectHandler handles HTTP requests for projects
+type ProjectHandler struct {
+	service *services.ProjectService
+	logger  *logger.Logger
+}
+
+// ProjectRequest represents a project request body. Without a workspace_id
+// the project is a personal project.
+type ProjectRequest struct {
+	Name        string `json:"name"`
+	WorkspaceID *int   `json:"workspace_id,omitempty"`
+}
+
+// NewProjectHandler creates a new ProjectHandler
+func NewProjectHandler(service *services.ProjectService, logger *logger.Logger) *ProjectHandler {
+	return &ProjectHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the projects visible to the current user
+func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.service.List(actor(r))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get projects")
+		return
+	}
+
+	respondJSON(w, projects, http.StatusOK)
+}
+
+// Create adds a new project
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.service.Create(actor(r), req.Name, req.WorkspaceID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create project")
+		return
+	}
+
+	respondJSON(w, project, http.StatusCreated)
+}
+
+// Get returns a specific project
+func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.service.Get(actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get project")
+		return
+	}
+
+	respondJSON(w, project, http.StatusOK)
+}
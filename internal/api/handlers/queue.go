@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// QueueHandler handles HTTP requests for the background job queue
+type QueueHandler struct {
+	service *services.QueueService
+	logger  *logger.Logger
+}
+
+// NewQueueHandler creates a new QueueHandler
+func NewQueueHandler(service *services.QueueService, logger *logger.Logger) *QueueHandler {
+	return &QueueHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the most recent queued jobs, optionally filtered by ?status=
+func (h *QueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.service.List(r.URL.Query().Get("status"))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to list queued jobs")
+		return
+	}
+
+	respondJSON(w, jobs, http.StatusOK)
+}
+
+// Retry queues a dead job again
+func (h *QueueHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.service.Retry(id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to retry job")
+		return
+	}
+
+	respondJSON(w, job, http.StatusOK)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// ReminderHandler handles HTTP requests for task reminders
+type ReminderHandler struct {
+	service *services.ReminderService
+	logger  *logger.Logger
+}
+
+// ReminderRequest represents a reminder request body
+type ReminderRequest struct {
+	At        string `json:"at"`
+	BeforeDue string `json:"before_due"`
+}
+
+// NewReminderHandler creates a new ReminderHandler
+func NewReminderHandler(service *services.ReminderService, logger *logger.Logger) *ReminderHandler {
+	return &ReminderHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the current user's reminders on a task
+func (h *ReminderHandler) List(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	reminders, err := h.service.List(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get reminders")
+		return
+	}
+
+	respondJSON(w, reminders, http.StatusOK)
+}
+
+// Create schedules a reminder about a task for the current user
+func (h *ReminderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reminder, err := h.service.Create(r.Context(), actor(r), taskID, services.ReminderInput{
+		At:        req.At,
+		BeforeDue: req.BeforeDue,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create reminder")
+		return
+	}
+
+	respondJSON(w, reminder, http.StatusCreated)
+}
+
+// Delete cancels a reminder of the current user
+func (h *ReminderHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	id, err := intParam(r, "reminderID")
+	if err != nil {
+		http.Error(w, "Invalid reminder ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), actor(r), taskID, id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete reminder")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
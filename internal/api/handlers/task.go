@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
+	"golang_task_manager_folder_structure/internal/api/middlewares"
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
 	"golang_task_manager_folder_structure/internal/services"
 
 	"github.com/go-chi/chi/v5"
@@ -14,6 +17,7 @@ import (
 // TaskHandler handles HTTP requests for tasks
 type TaskHandler struct {
 	service *services.TaskService
+	boards  *services.BoardService
 	logger  *logger.Logger
 }
 
@@ -22,21 +26,124 @@ type TaskRequest struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	DueDate     string `json:"due_date,omitempty"`
+	WorkspaceID *int   `json:"workspace_id,omitempty"`
+	AssigneeID  *int   `json:"assignee_id,omitempty"`
+	ProjectID   *int   `json:"project_id,omitempty"`
+	ParentID    *int   `json:"parent_id,omitempty"`
+	// EstimateMinutes of 0 clears the estimate on updates
+	EstimateMinutes *int `json:"estimate_minutes,omitempty"`
+}
+
+// AssignRequest represents an assignment request body. A null assignee_id
+// unassigns the task.
+type AssignRequest struct {
+	AssigneeID *int `json:"assignee_id"`
+}
+
+// MoveRequest represents a task move request body. It either places the task
+// in a board column or moves it to another project.
+type MoveRequest struct {
+	ColumnID  *int `json:"column_id,omitempty"`
+	AfterID   *int `json:"after_id,omitempty"`
+	BeforeID  *int `json:"before_id,omitempty"`
+	ProjectID *int `json:"project_id,omitempty"`
+}
+
+// SnoozeRequest represents a task snooze request body
+type SnoozeRequest struct {
+	// Until is an offset from now like "3d", a date or an RFC 3339 timestamp
+	Until string `json:"until"`
+}
+
+// DuplicateRequest represents a task duplication request body
+type DuplicateRequest struct {
+	Title       string `json:"title"`
+	Subtasks    bool   `json:"subtasks"`
+	Checklist   bool   `json:"checklist"`
+	Tags        bool   `json:"tags"`
+	Attachments bool   `json:"attachments"`
+	Comments    bool   `json:"comments"`
+}
+
+// QuickAddRequest represents a quick-add request body
+type QuickAddRequest struct {
+	Text string `json:"text"`
+}
+
+// QuickAddResponse holds the parsed interpretation and the created task
+type QuickAddResponse struct {
+	Parsed *services.QuickAddResult `json:"parsed"`
+	Task   *repository.Task         `json:"task,omitempty"`
 }
 
 // NewTaskHandler creates a new TaskHandler
-func NewTaskHandler(service *services.TaskService, logger *logger.Logger) *TaskHandler {
+func NewTaskHandler(service *services.TaskService, boards *services.BoardService, logger *logger.Logger) *TaskHandler {
 	return &TaskHandler{
 		service: service,
+		boards:  boards,
 		logger:  logger,
 	}
 }
 
-// List returns all tasks
+// List returns all tasks, optionally filtered by ?assignee=me|<id>, ?workspace=<id>,
+// ?project=<id>, ?parent=<id> and ?status=<status>. ?sort=rank lists them in manual order.
+// Snoozed tasks are left out unless ?include_deferred=true.
 func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.GetAll()
+	var filter repository.TaskFilter
+
+	if assignee := r.URL.Query().Get("assignee"); assignee == "me" {
+		user := middlewares.UserFromContext(r.Context())
+		filter.AssigneeID = &user.ID
+	} else if assignee != "" {
+		id, err := strconv.Atoi(assignee)
+		if err != nil {
+			http.Error(w, "Invalid assignee", http.StatusBadRequest)
+			return
+		}
+		filter.AssigneeID = &id
+	}
+
+	if workspace := r.URL.Query().Get("workspace"); workspace != "" {
+		id, err := strconv.Atoi(workspace)
+		if err != nil {
+			http.Error(w, "Invalid workspace", http.StatusBadRequest)
+			return
+		}
+		filter.WorkspaceID = &id
+	}
+
+	if project := r.URL.Query().Get("project"); project != "" {
+		id, err := strconv.Atoi(project)
+		if err != nil {
+			http.Error(w, "Invalid project", http.StatusBadRequest)
+			return
+		}
+		filter.ProjectID = &id
+	}
+
+	if parent := r.URL.Query().Get("parent"); parent != "" {
+		id, err := strconv.Atoi(parent)
+		if err != nil {
+			http.Error(w, "Invalid parent", http.StatusBadRequest)
+			return
+		}
+		filter.ParentID = &id
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		filter.Status = &status
+	}
+
+	filter.OrderByRank = r.URL.Query().Get("sort") == "rank"
+
+	if includeDeferred, _ := strconv.ParseBool(r.URL.Query().Get("include_deferred")); !includeDeferred {
+		now := time.Now().UTC()
+		filter.AvailableAt = &now
+	}
+
+	tasks, err := h.service.GetAll(r.Context(), actor(r), filter)
 	if err != nil {
-		h.logger.Error("Failed to get tasks", err)
+		middlewares.RequestLogger(r, h.logger).Error("Failed to get tasks", err)
 		http.Error(w, "Failed to get tasks", http.StatusInternalServerError)
 		return
 	}
@@ -53,10 +160,9 @@ func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.GetByID(id)
+	task, err := h.service.GetByID(r.Context(), actor(r), id)
 	if err != nil {
-		h.logger.Error("Failed to get task", err)
-		http.Error(w, "Task not found", http.StatusNotFound)
+		respondError(w, r, h.logger, err, "Failed to get task")
 		return
 	}
 
@@ -76,16 +182,53 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.Create(req.Title, req.Description, req.DueDate)
+	task, err := h.service.Create(r.Context(), actor(r), services.TaskInput{
+		Title:           req.Title,
+		Description:     req.Description,
+		DueDate:         req.DueDate,
+		WorkspaceID:     req.WorkspaceID,
+		AssigneeID:      req.AssigneeID,
+		ProjectID:       req.ProjectID,
+		ParentID:        req.ParentID,
+		EstimateMinutes: req.EstimateMinutes,
+	})
 	if err != nil {
-		h.logger.Error("Failed to create task", err)
-		http.Error(w, "Failed to create task", http.StatusInternalServerError)
+		respondError(w, r, h.logger, err, "Failed to create task")
 		return
 	}
 
 	respondJSON(w, task, http.StatusCreated)
 }
 
+// QuickAdd creates a task from a single line of text
+func (h *TaskHandler) QuickAdd(w http.ResponseWriter, r *http.Request) {
+	var req QuickAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Text == "" {
+		http.Error(w, "Text is required", http.StatusBadRequest)
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	parsed, task, err := h.service.QuickAdd(r.Context(), actor(r), req.Text, dryRun)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create task")
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	respondJSON(w, QuickAddResponse{Parsed: parsed, Task: task}, status)
+}
+
 // Update modifies an existing task
 func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
@@ -101,16 +244,60 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.Update(id, req.Title, req.Description, req.DueDate)
+	task, err := h.service.Update(r.Context(), actor(r), id, services.TaskUpdate{
+		Title:           req.Title,
+		Description:     req.Description,
+		DueDate:         req.DueDate,
+		EstimateMinutes: req.EstimateMinutes,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to update task")
+		return
+	}
+
+	respondJSON(w, task, http.StatusOK)
+}
+
+// Assign changes the assignee of a task
+func (h *TaskHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req AssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.service.Assign(r.Context(), actor(r), id, req.AssigneeID)
 	if err != nil {
-		h.logger.Error("Failed to update task", err)
-		http.Error(w, "Failed to update task", http.StatusInternalServerError)
+		respondError(w, r, h.logger, err, "Failed to assign task")
 		return
 	}
 
 	respondJSON(w, task, http.StatusOK)
 }
 
+// History returns the change history of a task
+func (h *TaskHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.service.History(r.Context(), actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get task history")
+		return
+	}
+
+	respondJSON(w, entries, http.StatusOK)
+}
+
 // Delete removes a task
 func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
@@ -120,9 +307,8 @@ func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
-		h.logger.Error("Failed to delete task", err)
-		http.Error(w, "Failed to delete task", http.StatusInternalServerError)
+	if err := h.service.Delete(r.Context(), actor(r), id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete task")
 		return
 	}
 
@@ -138,10 +324,119 @@ func (h *TaskHandler) Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.Complete(id)
+	task, err := h.service.Complete(r.Context(), actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to complete task")
+		return
+	}
+
+	respondJSON(w, task, http.StatusOK)
+}
+
+// Move places a task in a board column next to a neighbor, or moves it with
+// its subtasks to another project
+func (h *TaskHandler) Move(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req MoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if (req.ColumnID == nil) == (req.ProjectID == nil) {
+		http.Error(w, "Either column_id or project_id is required", http.StatusBadRequest)
+		return
+	}
+
+	var task *repository.Task
+	if req.ProjectID != nil {
+		task, err = h.service.MoveToProject(r.Context(), actor(r), id, *req.ProjectID)
+	} else {
+		task, err = h.boards.Move(r.Context(), actor(r), id, services.MoveInput{
+			ColumnID: *req.ColumnID,
+			AfterID:  req.AfterID,
+			BeforeID: req.BeforeID,
+		})
+	}
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to move task")
+		return
+	}
+
+	respondJSON(w, task, http.StatusOK)
+}
+
+// Duplicate copies a task next to the original
+func (h *TaskHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req DuplicateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	task, err := h.service.Duplicate(r.Context(), actor(r), id, services.DuplicateOptions{
+		Title:       req.Title,
+		Subtasks:    req.Subtasks,
+		Checklist:   req.Checklist,
+		Tags:        req.Tags,
+		Attachments: req.Attachments,
+		Comments:    req.Comments,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to duplicate task")
+		return
+	}
+
+	respondJSON(w, task, http.StatusCreated)
+}
+
+// Snooze hides a task from lists until a later time
+func (h *TaskHandler) Snooze(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req SnoozeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.service.Snooze(r.Context(), actor(r), id, req.Until)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to snooze task")
+		return
+	}
+
+	respondJSON(w, task, http.StatusOK)
+}
+
+// Unsnooze makes a snoozed task available again
+func (h *TaskHandler) Unsnooze(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.service.Unsnooze(r.Context(), actor(r), id)
 	if err != nil {
-		h.logger.Error("Failed to complete task", err)
-		http.Error(w, "Failed to complete task", http.StatusInternalServerError)
+		respondError(w, r, h.logger, err, "Failed to unsnooze task")
 		return
 	}
 
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// TemplateHandler handles HTTP requests for task templates
+type TemplateHandler struct {
+	service *services.TemplateService
+	logger  *logger.Logger
+}
+
+// TemplateRequest represents a template request body. The workspace is only
+// used when creating a template.
+type TemplateRequest struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	WorkspaceID *int                      `json:"workspace_id,omitempty"`
+	Tasks       []repository.TemplateTask `json:"tasks"`
+}
+
+// InstantiateRequest represents a template instantiation request body
+type InstantiateRequest struct {
+	Variables   map[string]string `json:"variables"`
+	AnchorDate  string            `json:"anchor_date,omitempty"`
+	WorkspaceID *int              `json:"workspace_id,omitempty"`
+	ProjectID   *int              `json:"project_id,omitempty"`
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler(service *services.TemplateService, logger *logger.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the templates visible to the current user
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.List(actor(r))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get templates")
+		return
+	}
+
+	respondJSON(w, templates, http.StatusOK)
+}
+
+// Create adds a new template
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.service.Create(actor(r), services.TemplateInput{
+		Name:        req.Name,
+		Description: req.Description,
+		WorkspaceID: req.WorkspaceID,
+		Tasks:       req.Tasks,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create template")
+		return
+	}
+
+	respondJSON(w, template, http.StatusCreated)
+}
+
+// Get returns a specific template
+func (h *TemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.service.Get(actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get template")
+		return
+	}
+
+	respondJSON(w, template, http.StatusOK)
+}
+
+// Update replaces a template
+func (h *TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.service.Update(actor(r), id, services.TemplateInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Tasks:       req.Tasks,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to update template")
+		return
+	}
+
+	respondJSON(w, template, http.StatusOK)
+}
+
+// Delete removes a template
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(actor(r), id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Instantiate creates the tasks of a template
+func (h *TemplateHandler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	var req InstantiateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := h.service.Instantiate(r.Context(), actor(r), id, services.InstantiateInput{
+		Variables:   req.Variables,
+		AnchorDate:  req.AnchorDate,
+		WorkspaceID: req.WorkspaceID,
+		ProjectID:   req.ProjectID,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to instantiate template")
+		return
+	}
+
+	respondJSON(w, tasks, http.StatusCreated)
+}
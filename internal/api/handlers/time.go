@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/api/middlewares"
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// TimeHandler handles HTTP requests for timers, time entries and time reports
+type TimeHandler struct {
+	service *services.TimeService
+	logger  *logger.Logger
+}
+
+// TimerRequest represents a timer start request body
+type TimerRequest struct {
+	Note string `json:"note"`
+}
+
+// TimeEntryRequest represents a time entry request body. Times are RFC 3339;
+// fields left out of an update keep their value.
+type TimeEntryRequest struct {
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Note      *string    `json:"note"`
+}
+
+// NewTimeHandler creates a new TimeHandler
+func NewTimeHandler(service *services.TimeService, logger *logger.Logger) *TimeHandler {
+	return &TimeHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Start starts a timer on a task for the current user
+func (h *TimeHandler) Start(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TimerRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entry, err := h.service.Start(r.Context(), actor(r), taskID, req.Note)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to start timer")
+		return
+	}
+
+	respondJSON(w, entry, http.StatusCreated)
+}
+
+// Stop stops the current user's running timer on a task
+func (h *TimeHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.Stop(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to stop timer")
+		return
+	}
+
+	respondJSON(w, entry, http.StatusOK)
+}
+
+// List returns the time entries and estimate of a task
+func (h *TimeHandler) List(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.service.List(r.Context(), actor(r), taskID)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get time entries")
+		return
+	}
+
+	respondJSON(w, summary, http.StatusOK)
+}
+
+// Create records a manual time entry on a task
+func (h *TimeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	taskID, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TimeEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.Create(r.Context(), actor(r), taskID, services.TimeEntryInput{
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Note:      req.Note,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create time entry")
+		return
+	}
+
+	respondJSON(w, entry, http.StatusCreated)
+}
+
+// Update edits a time entry of the current user
+func (h *TimeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid time entry ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TimeEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.Update(r.Context(), actor(r), id, services.TimeEntryInput{
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Note:      req.Note,
+	})
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to update time entry")
+		return
+	}
+
+	respondJSON(w, entry, http.StatusOK)
+}
+
+// Delete removes a time entry of the current user
+func (h *TimeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid time entry ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), actor(r), id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to delete time entry")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Report aggregates tracked time. It accepts ?from= and ?to= (inclusive
+// YYYY-MM-DD dates), ?user=me|<id>, ?project=<id>, ?group_by=day|project|tag
+// and ?format=csv.
+func (h *TimeHandler) Report(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := services.ReportQuery{GroupBy: q.Get("group_by")}
+
+	if from := q.Get("from"); from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		query.From = &date
+	}
+
+	if to := q.Get("to"); to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		end := date.AddDate(0, 0, 1)
+		query.To = &end
+	}
+
+	if user := q.Get("user"); user == "me" {
+		id := middlewares.UserFromContext(r.Context()).ID
+		query.UserID = &id
+	} else if user != "" {
+		id, err := strconv.Atoi(user)
+		if err != nil {
+			http.Error(w, "Invalid user", http.StatusBadRequest)
+			return
+		}
+		query.UserID = &id
+	}
+
+	if project := q.Get("project"); project != "" {
+		id, err := strconv.Atoi(project)
+		if err != nil {
+			http.Error(w, "Invalid project", http.StatusBadRequest)
+			return
+		}
+		query.ProjectID = &id
+	}
+
+	report, err := h.service.Report(r.Context(), actor(r), query)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to build time report")
+		return
+	}
+
+	if q.Get("format") != "csv" {
+		respondJSON(w, report, http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="time-by-%s.csv"`, report.GroupBy))
+
+	out := csv.NewWriter(w)
+	out.Write([]string{report.GroupBy, "minutes", "hours", "entries"})
+	for _, row := range report.Rows {
+		out.Write([]string{row.Key, strconv.Itoa(row.Minutes), formatHours(row.Minutes), strconv.Itoa(row.Entries)})
+	}
+	out.Write([]string{"total", strconv.Itoa(report.TotalMinutes), formatHours(report.TotalMinutes), ""})
+	out.Flush()
+
+	if err := out.Error(); err != nil {
+		middlewares.RequestLogger(r, h.logger).Error("Failed to write time report", err)
+	}
+}
+
+func formatHours(minutes int) string {
+	return strconv.FormatFloat(float64(minutes)/60, 'f', 2, 64)
+}
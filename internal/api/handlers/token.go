@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// TokenHandler handles HTTP requests for personal API tokens
+type TokenHandler struct {
+	service *services.TokenService
+	logger  *logger.Logger
+}
+
+// TokenRequest represents a token request body
+type TokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// TokenResponse holds a newly created token. The plaintext token is only
+// ever returned here.
+type TokenResponse struct {
+	Token    string               `json:"token"`
+	APIToken *repository.APIToken `json:"api_token"`
+}
+
+// NewTokenHandler creates a new TokenHandler
+func NewTokenHandler(service *services.TokenService, logger *logger.Logger) *TokenHandler {
+	return &TokenHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the current user's tokens
+func (h *TokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.service.List(actor(r))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get tokens")
+		return
+	}
+
+	respondJSON(w, tokens, http.StatusOK)
+}
+
+// Create mints a new token
+func (h *TokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	plaintext, token, err := h.service.Create(actor(r), req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create token")
+		return
+	}
+
+	respondJSON(w, TokenResponse{Token: plaintext, APIToken: token}, http.StatusCreated)
+}
+
+// Revoke disables a token
+func (h *TokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Revoke(actor(r), id); err != nil {
+		respondError(w, r, h.logger, err, "Failed to revoke token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// WorkspaceHandler handles HTTP requests for workspaces
+type WorkspaceHandler struct {
+	service *services.WorkspaceService
+	logger  *logger.Logger
+}
+
+// WorkspaceRequest represents a workspace request body
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// MemberRequest represents a workspace member request body
+type MemberRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// NewWorkspaceHandler creates a new WorkspaceHandler
+func NewWorkspaceHandler(service *services.WorkspaceService, logger *logger.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns the workspaces of the current user
+func (h *WorkspaceHandler) List(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := h.service.List(actor(r))
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get workspaces")
+		return
+	}
+
+	respondJSON(w, workspaces, http.StatusOK)
+}
+
+// Create adds a new workspace owned by the current user
+func (h *WorkspaceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req WorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := h.service.Create(actor(r), req.Name)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to create workspace")
+		return
+	}
+
+	respondJSON(w, workspace, http.StatusCreated)
+}
+
+// Get returns a specific workspace
+func (h *WorkspaceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := h.service.Get(actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get workspace")
+		return
+	}
+
+	respondJSON(w, workspace, http.StatusOK)
+}
+
+// Members returns the members of a workspace
+func (h *WorkspaceHandler) Members(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+		return
+	}
+
+	members, err := h.service.Members(actor(r), id)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to get workspace members")
+		return
+	}
+
+	respondJSON(w, members, http.StatusOK)
+}
+
+// SaveMember adds a member to a workspace or changes their role
+func (h *WorkspaceHandler) SaveMember(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+		return
+	}
+
+	var req MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	member, err := h.service.SaveMember(actor(r), id, req.Username, req.Role)
+	if err != nil {
+		respondError(w, r, h.logger, err, "Failed to save workspace member")
+		return
+	}
+
+	respondJSON(w, member, http.StatusOK)
+}
+
+// RemoveMember removes a member from a workspace
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, err := intParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+		return
+	}
+
+	memberID, err := intParam(r, "userID")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RemoveMember(actor(r), id, memberID); err != nil {
+		respondError(w, r, h.logger, err, "Failed to remove workspace member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
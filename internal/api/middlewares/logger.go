@@ -1,35 +1,128 @@
 package middlewares
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"time"
 
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// LoggerMiddleware logs HTTP requests
+const accessLogContextKey contextKey = "accessLog"
+
+// accessLog collects what the access log line learns while a request is
+// served by inner middlewares
+type accessLog struct {
+	userID *int
+}
+
+// LoggerMiddleware logs HTTP requests and stores a request-scoped logger,
+// tagged with the request ID, remote IP and trace ID, in the request context
 func LoggerMiddleware(l *logger.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			requestLogger := l.With(
+				"request_id", middleware.GetReqID(r.Context()),
+				"remote_ip", remoteIP(r),
+			)
+			if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+				requestLogger = requestLogger.With("trace_id", span.TraceID().String())
+			}
+			access := &accessLog{}
+			ctx := logger.NewContext(r.Context(), requestLogger)
+			ctx = context.WithValue(ctx, accessLogContextKey, access)
+
 			// Capture response with a wrapper
 			ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 			// Process request
-			next.ServeHTTP(ww, r)
+			next.ServeHTTP(ww, r.WithContext(ctx))
 
 			// Log request details
-			duration := time.Since(start)
-			l.Info("%s %s %d %s", r.Method, r.RequestURI, ww.statusCode, duration)
+			fields := []interface{}{
+				"method", r.Method,
+				"uri", r.RequestURI,
+				"route", routePattern(r),
+				"status", ww.statusCode,
+				"bytes_written", ww.bytesWritten,
+				"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if access.userID != nil {
+				fields = append(fields, "user_id", *access.userID)
+			}
+			requestLogger.Info("Request served", fields...)
+		})
+	}
+}
+
+// MetricsMiddleware counts requests and measures their latency by route
+// pattern and status
+func MetricsMiddleware(m *metrics.HTTP) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(ww, r)
+
+			m.Observe(r.Method, routePattern(r), ww.statusCode, time.Since(start))
 		})
 	}
 }
 
-// responseWriter is a wrapper for http.ResponseWriter to capture the status code
+// RequestLogger returns the request-scoped logger, tagged with the route
+// pattern, or fallback outside of LoggerMiddleware
+func RequestLogger(r *http.Request, fallback *logger.Logger) *logger.Logger {
+	l := logger.FromContext(r.Context(), fallback)
+	if route := routePattern(r); route != "" {
+		l = l.With("route", route)
+	}
+	return l
+}
+
+// withUser tags the request-scoped logger and the access log line with the
+// authenticated user
+func withUser(ctx context.Context, userID int) context.Context {
+	if access, ok := ctx.Value(accessLogContextKey).(*accessLog); ok {
+		access.userID = &userID
+	}
+	if l := logger.FromContext(ctx, nil); l != nil {
+		ctx = logger.NewContext(ctx, l.With("user_id", userID))
+	}
+	return ctx
+}
+
+// remoteIP returns the client address without its port. RealIP has already
+// replaced it with the forwarded address when there is one.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// routePattern returns the pattern of the route the request matched so far
+func routePattern(r *http.Request) string {
+	if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+		return routeContext.RoutePattern()
+	}
+	return ""
+}
+
+// responseWriter is a wrapper for http.ResponseWriter to capture the status
+// code and the number of body bytes written
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	bytesWritten int64
 }
 
 // WriteHeader captures the status code
@@ -37,3 +130,15 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Write counts the bytes written to the body
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytesWritten += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
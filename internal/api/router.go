@@ -4,36 +4,181 @@ import (
 	"golang_task_manager_folder_structure/internal/api/handlers"
 	"golang_task_manager_folder_structure/internal/api/middlewares"
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
+	"golang_task_manager_folder_structure/internal/services"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
+// Handlers contains all HTTP handlers served by the router
+type Handlers struct {
+	Task         *handlers.TaskHandler
+	Health       *handlers.HealthHandler
+	Auth         *handlers.AuthHandler
+	Workspace    *handlers.WorkspaceHandler
+	Notification *handlers.NotificationHandler
+	Token        *handlers.TokenHandler
+	Comment      *handlers.CommentHandler
+	Attachment   *handlers.AttachmentHandler
+	Project      *handlers.ProjectHandler
+	Time         *handlers.TimeHandler
+	Checklist    *handlers.ChecklistHandler
+	Board        *handlers.BoardHandler
+	Template     *handlers.TemplateHandler
+	Reminder     *handlers.ReminderHandler
+	Digest       *handlers.DigestHandler
+	Job          *handlers.JobHandler
+	Queue        *handlers.QueueHandler
+}
+
 // setupRouter configures the router with all routes and middlewares
-func setupRouter(taskHandler *handlers.TaskHandler, healthHandler *handlers.HealthHandler, logger *logger.Logger) *chi.Mux {
+func setupRouter(h *Handlers, authService *services.AuthService, tokenService *services.TokenService, admins []string, httpMetrics *metrics.HTTP, logger *logger.Logger) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middlewares
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(middlewares.TracingMiddleware())
 	r.Use(middlewares.LoggerMiddleware(logger))
+	r.Use(middlewares.MetricsMiddleware(httpMetrics))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	// Routes
-	r.Get("/health", healthHandler.Check)
+	r.Get("/health", h.Health.Check)
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
-		r.Route("/tasks", func(r chi.Router) {
-			r.Get("/", taskHandler.List)
-			r.Post("/", taskHandler.Create)
-			r.Route("/{id}", func(r chi.Router) {
-				r.Get("/", taskHandler.Get)
-				r.Put("/", taskHandler.Update)
-				r.Delete("/", taskHandler.Delete)
-				r.Put("/complete", taskHandler.Complete)
+		r.Post("/auth/register", h.Auth.Register)
+		r.Post("/auth/login", h.Auth.Login)
+
+		// Authenticated routes
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.AuthMiddleware(authService, tokenService))
+
+			r.Get("/auth/me", h.Auth.Me)
+
+			r.Route("/tokens", func(r chi.Router) {
+				r.Use(middlewares.RequireSession)
+				r.Get("/", h.Token.List)
+				r.Post("/", h.Token.Create)
+				r.Delete("/{id}", h.Token.Revoke)
+			})
+
+			r.Route("/tasks", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("tasks"))
+				r.Get("/", h.Task.List)
+				r.Post("/", h.Task.Create)
+				r.Post("/quick", h.Task.QuickAdd)
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", h.Task.Get)
+					r.Put("/", h.Task.Update)
+					r.Delete("/", h.Task.Delete)
+					r.Put("/complete", h.Task.Complete)
+					r.Put("/assign", h.Task.Assign)
+					r.Post("/move", h.Task.Move)
+					r.Post("/duplicate", h.Task.Duplicate)
+					r.Post("/snooze", h.Task.Snooze)
+					r.Delete("/snooze", h.Task.Unsnooze)
+					r.Get("/history", h.Task.History)
+					r.Get("/activity", h.Comment.Activity)
+					r.Route("/comments", func(r chi.Router) {
+						r.Get("/", h.Comment.List)
+						r.Post("/", h.Comment.Create)
+						r.Put("/{commentID}", h.Comment.Update)
+						r.Delete("/{commentID}", h.Comment.Delete)
+					})
+					r.Route("/attachments", func(r chi.Router) {
+						r.Get("/", h.Attachment.List)
+						r.Post("/", h.Attachment.Upload)
+						r.Get("/{attachmentID}", h.Attachment.Download)
+						r.Delete("/{attachmentID}", h.Attachment.Delete)
+					})
+					r.Route("/checklist", func(r chi.Router) {
+						r.Get("/", h.Checklist.List)
+						r.Post("/", h.Checklist.Add)
+						r.Put("/order", h.Checklist.Reorder)
+						r.Put("/{itemID}", h.Checklist.Update)
+						r.Post("/{itemID}/toggle", h.Checklist.Toggle)
+						r.Delete("/{itemID}", h.Checklist.Delete)
+					})
+					r.Route("/reminders", func(r chi.Router) {
+						r.Get("/", h.Reminder.List)
+						r.Post("/", h.Reminder.Create)
+						r.Delete("/{reminderID}", h.Reminder.Delete)
+					})
+					r.Post("/timer/start", h.Time.Start)
+					r.Post("/timer/stop", h.Time.Stop)
+					r.Get("/time-entries", h.Time.List)
+					r.Post("/time-entries", h.Time.Create)
+				})
+			})
+
+			r.Route("/projects", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("tasks"))
+				r.Get("/", h.Project.List)
+				r.Post("/", h.Project.Create)
+				r.Get("/{id}", h.Project.Get)
+			})
+
+			r.Route("/boards", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("tasks"))
+				r.Get("/", h.Board.List)
+				r.Post("/", h.Board.Create)
+				r.Get("/{id}", h.Board.Get)
+				r.Put("/{id}/columns", h.Board.SaveColumns)
+			})
+
+			r.Route("/templates", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("tasks"))
+				r.Get("/", h.Template.List)
+				r.Post("/", h.Template.Create)
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", h.Template.Get)
+					r.Put("/", h.Template.Update)
+					r.Delete("/", h.Template.Delete)
+					r.Post("/instantiate", h.Template.Instantiate)
+				})
+			})
+
+			r.Route("/time-entries", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("tasks"))
+				r.Put("/{id}", h.Time.Update)
+				r.Delete("/{id}", h.Time.Delete)
+			})
+
+			r.With(middlewares.RequireScope("tasks")).Get("/reports/time", h.Time.Report)
+
+			r.Route("/workspaces", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("workspaces"))
+				r.Get("/", h.Workspace.List)
+				r.Post("/", h.Workspace.Create)
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", h.Workspace.Get)
+					r.Get("/members", h.Workspace.Members)
+					r.Put("/members", h.Workspace.SaveMember)
+					r.Delete("/members/{userID}", h.Workspace.RemoveMember)
+				})
+			})
+
+			r.Route("/notifications", func(r chi.Router) {
+				r.Use(middlewares.RequireScope("notifications"))
+				r.Get("/", h.Notification.List)
+				r.Get("/digest", h.Digest.Get)
+				r.Put("/digest", h.Digest.Save)
+				r.Put("/{id}/read", h.Notification.MarkRead)
+			})
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(middlewares.RequireSession)
+				r.Use(middlewares.RequireAdmin(admins))
+				r.Get("/jobs", h.Job.List)
+				r.Get("/jobs/{name}/runs", h.Job.Runs)
+				r.Post("/jobs/{name}/run", h.Job.Run)
+				r.Get("/queue", h.Queue.List)
+				r.Post("/queue/{id}/retry", h.Queue.Retry)
 			})
 		})
 	})
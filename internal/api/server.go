@@ -4,28 +4,76 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
 	"time"
 
 	"golang_task_manager_folder_structure/internal/api/handlers"
 	"golang_task_manager_folder_structure/internal/config"
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
 	"golang_task_manager_folder_structure/internal/repository"
 	"golang_task_manager_folder_structure/internal/services"
+	"golang_task_manager_folder_structure/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Services contains all service dependencies
 type Services struct {
-	TaskService *services.TaskService
-	Logger      *logger.Logger
+	TaskService         *services.TaskService
+	AuthService         *services.AuthService
+	WorkspaceService    *services.WorkspaceService
+	NotificationService *services.NotificationService
+	TokenService        *services.TokenService
+	CommentService      *services.CommentService
+	AttachmentService   *services.AttachmentService
+	ProjectService      *services.ProjectService
+	TimeService         *services.TimeService
+	ChecklistService    *services.ChecklistService
+	BoardService        *services.BoardService
+	TemplateService     *services.TemplateService
+	ReminderService     *services.ReminderService
+	DigestService       *services.DigestService
+	JobService          *services.JobService
+	QueueService        *services.QueueService
+	Logger              *logger.Logger
 }
 
 // NewServices creates a new Services instance
-func NewServices(taskRepo *repository.TaskRepository, logger *logger.Logger) *Services {
+func NewServices(cfg *config.Config, repos *repository.Repositories, blobs storage.BlobStore, logger *logger.Logger) *Services {
+	notifier := notify.NewInboxNotifier(repos.Notifications, logger)
+	policy := policy.New(repos.Workspaces)
+	attachmentService := services.NewAttachmentService(repos, policy, blobs, services.AttachmentLimits{
+		MaxBytes:     cfg.AttachmentMaxBytes,
+		AllowedTypes: cfg.AttachmentAllowedTypes,
+	}, logger)
+	queue := services.NewQueueService(repos, services.QueueOptions{
+		MaxAttempts:       cfg.QueueMaxAttempts,
+		VisibilityTimeout: time.Duration(cfg.QueueVisibilityTimeout) * time.Second,
+	})
+	taskService := services.NewTaskService(repos, policy, queue, attachmentService, services.TaskOptions{
+		RequireChecklistComplete: cfg.RequireChecklistComplete,
+	}, logger)
+
 	return &Services{
-		TaskService: services.NewTaskService(taskRepo),
-		Logger:      logger,
+		TaskService:         taskService,
+		AuthService:         services.NewAuthService(repos.Users, cfg.JWTSecret),
+		WorkspaceService:    services.NewWorkspaceService(repos.Workspaces, repos.Users, policy),
+		NotificationService: services.NewNotificationService(repos.Notifications),
+		TokenService:        services.NewTokenService(repos.Tokens, repos.Users),
+		CommentService:      services.NewCommentService(repos, policy, queue, logger),
+		AttachmentService:   attachmentService,
+		ProjectService:      services.NewProjectService(repos.Projects, policy),
+		TimeService:         services.NewTimeService(repos, policy),
+		ChecklistService:    services.NewChecklistService(repos, policy),
+		BoardService:        services.NewBoardService(repos, policy),
+		TemplateService:     services.NewTemplateService(repos, policy),
+		ReminderService:     services.NewReminderService(repos, policy, notifier),
+		DigestService:       services.NewDigestService(repos),
+		JobService:          services.NewJobService(repos),
+		QueueService:        queue,
+		Logger:              logger,
 	}
 }
 
@@ -33,23 +81,43 @@ func NewServices(taskRepo *repository.TaskRepository, logger *logger.Logger) *Se
 type Server struct {
 	router   http.Handler
 	server   *http.Server
+	metrics  *http.Server
 	services *Services
 	logger   *logger.Logger
 }
 
-// NewServer creates a new Server instance
-func NewServer(cfg *config.Config, services *Services, logger *logger.Logger) *Server {
+// NewServer creates a new Server instance. The given registry, to which the
+// HTTP request metrics are added, is served on /metrics of a separate port
+// that is not exposed with the API.
+func NewServer(cfg *config.Config, services *Services, registry *prometheus.Registry, logger *logger.Logger) *Server {
 	server := &Server{
 		services: services,
 		logger:   logger,
 	}
 
 	// Initialize handlers
-	taskHandler := handlers.NewTaskHandler(services.TaskService, logger)
-	healthHandler := handlers.NewHealthHandler(logger)
+	h := &Handlers{
+		Task:         handlers.NewTaskHandler(services.TaskService, services.BoardService, logger),
+		Health:       handlers.NewHealthHandler(logger),
+		Auth:         handlers.NewAuthHandler(services.AuthService, logger),
+		Workspace:    handlers.NewWorkspaceHandler(services.WorkspaceService, logger),
+		Notification: handlers.NewNotificationHandler(services.NotificationService, logger),
+		Token:        handlers.NewTokenHandler(services.TokenService, logger),
+		Comment:      handlers.NewCommentHandler(services.CommentService, logger),
+		Attachment:   handlers.NewAttachmentHandler(services.AttachmentService, logger),
+		Project:      handlers.NewProjectHandler(services.ProjectService, logger),
+		Time:         handlers.NewTimeHandler(services.TimeService, logger),
+		Checklist:    handlers.NewChecklistHandler(services.ChecklistService, logger),
+		Board:        handlers.NewBoardHandler(services.BoardService, logger),
+		Template:     handlers.NewTemplateHandler(services.TemplateService, logger),
+		Reminder:     handlers.NewReminderHandler(services.ReminderService, logger),
+		Digest:       handlers.NewDigestHandler(services.DigestService, logger),
+		Job:          handlers.NewJobHandler(services.JobService, logger),
+		Queue:        handlers.NewQueueHandler(services.QueueService, logger),
+	}
 
 	// Initialize router
-	router := setupRouter(taskHandler, healthHandler, logger)
+	router := setupRouter(h, services.AuthService, services.TokenService, cfg.AdminUsers, metrics.NewHTTP(registry), logger)
 	server.router = router
 
 	// Configure HTTP server
@@ -61,29 +129,46 @@ func NewServer(cfg *config.Config, services *Services, logger *logger.Logger) *S
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Serve the metrics on their own port, like the cron binary
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler(registry))
+	server.metrics = &http.Server{
+		Addr:        fmt.Sprintf("%s:%d", cfg.ServerHost, cfg.APIMetricsPort),
+		Handler:     mux,
+		ReadTimeout: 15 * time.Second,
+	}
+
 	return server
 }
 
-// Start begins listening for requests
-func (s *Server) Start() error {
-	// Start server in a goroutine
-	go func() {
-		s.logger.Info("Server starting on %s", s.server.Addr)
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Fatal("Server failed to start", err)
+// Start serves requests until ctx is done and then shuts the server down.
+// It returns early with an error when the server cannot listen or fails, so
+// that the caller can shut down the rest of the process.
+func (s *Server) Start(ctx context.Context) error {
+	// Start the servers in goroutines
+	failed := make(chan error, 2)
+	serve := func(name string, server *http.Server) {
+		s.logger.Info(name+" starting", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			failed <- fmt.Errorf("serve on %s: %w", server.Addr, err)
 		}
-	}()
+	}
+	go serve("Server", s.server)
+	go serve("Metrics server", s.metrics)
+	defer s.metrics.Close()
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+	select {
+	case <-ctx.Done():
+	case err := <-failed:
+		s.server.Close()
+		return err
+	}
 
 	s.logger.Info("Server shutting down...")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := s.server.Shutdown(ctx); err != nil {
+	if err := s.server.Shutdown(shutdown); err != nil {
 		s.logger.Error("Server forced to shutdown", err)
 		return err
 	}
@@ -1,50 +1,298 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/robfig/cron/v3"
 )
 
+// defaultJobSchedules are the cron expressions the scheduled jobs run on
+// unless configured otherwise
+var defaultJobSchedules = map[string]string{
+	"wake-snoozed-tasks":     "* * * * *",
+	"send-digests":           "*/15 * * * *",
+	"escalate-overdue-tasks": "0 * * * *",
+	"cleanup-old-tasks":      "0 0 * * 0",
+}
+
+// JobConfig configures a scheduled job
+type JobConfig struct {
+	// Schedule is a standard five-field cron expression or a descriptor
+	// such as @hourly or @every 10m
+	Schedule string
+	Enabled  bool
+}
+
 // Config stores all configuration of the application
 type Config struct {
 	// Server configuration
 	ServerPort int
 	ServerHost string
+	// MetricsPort is where the cron binary serves /metrics, and
+	// APIMetricsPort where the API does, apart from its public port
+	MetricsPort    int
+	APIMetricsPort int
 	
 	// Database configuration
 	DatabaseURL string
 	
 	// Logger configuration
-	LogLevel string
+	LogLevel      string
+	LogFormat     string
+	LogOutput     string
+	LogMaxSizeMB  int
+	LogMaxBackups int
+
+	// Tracing configuration: the exporter (none, otlp or stdout) and where
+	// the stdout exporter writes spans to
+	TracingExporter string
+	TracingOutput   string
 	
-	// JWT Secret
+	// JWTSecret signs login tokens; the API refuses to start without it
 	JWTSecret string
+
+	// Attachment storage configuration
+	StorageBackend         string
+	StorageLocalDir        string
+	S3Endpoint             string
+	S3Region               string
+	S3Bucket               string
+	S3AccessKey            string
+	S3SecretKey            string
+	S3UseSSL               bool
+	AttachmentMaxBytes     int64
+	AttachmentAllowedTypes []string
+
+	// SMTP configuration for digest emails; digests are disabled without a host
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Escalation of overdue tasks; 0 hours disables a step
+	EscalateAssigneeAfterHours int
+	EscalateOwnerAfterHours    int
+	EscalateBumpPriority       bool
+
+	// RunScheduler is "embedded" to run the scheduled jobs inside the API
+	// process, or "separate" when they run in the cron binary
+	RunScheduler string
+
+	// SchedulerLockTTL is how long a cron instance keeps running the jobs
+	// after it stopped renewing its lock, in seconds
+	SchedulerLockTTL int
+
+	// SchedulerTimezone is the time zone job schedules are interpreted in
+	SchedulerTimezone *time.Location
+
+	// SchedulerShutdownTimeout is how long the cron binary waits for running
+	// jobs when it is asked to stop, in seconds
+	SchedulerShutdownTimeout int
+
+	// Jobs configures the scheduled jobs by name, read from
+	// JOB_<NAME>_SCHEDULE and JOB_<NAME>_ENABLED
+	Jobs map[string]JobConfig
+
+	// AdminUsers are the usernames allowed to use the admin API
+	AdminUsers []string
+
+	// Background job queue: workers per cron instance, attempts per job and
+	// how long a worker holds a job before others may retry it, in seconds
+	QueueWorkers           int
+	QueueMaxAttempts       int
+	QueueVisibilityTimeout int
+
+	// RequireChecklistComplete refuses to complete tasks with unchecked checklist items
+	RequireChecklistComplete bool
 }
 
-// Load reads configuration from environment variables
+// Load reads configuration from environment variables and, when CONFIG_FILE
+// names one, from a configuration file. Environment variables take
+// precedence over the file.
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	godotenv.Load()
-	
-	port, err := strconv.Atoi(getEnv("SERVER_PORT", "8080"))
+
+	vars := &envVars{}
+	if path := strings.TrimSpace(os.Getenv("CONFIG_FILE")); path != "" {
+		file, err := godotenv.Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("read CONFIG_FILE %q: %w", path, err)
+		}
+		vars.file = file
+	}
+
+	timezone := vars.get("SCHEDULER_TIMEZONE", "UTC")
+	location, err := time.LoadLocation(timezone)
 	if err != nil {
-		port = 8080
+		return nil, fmt.Errorf("invalid SCHEDULER_TIMEZONE %q: %w", timezone, err)
 	}
-	
-	return &Config{
-		ServerPort:  port,
-		ServerHost:  getEnv("SERVER_HOST", ""),
-		DatabaseURL: getEnv("DATABASE_URL", "sqlite3://tasks.db"),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
-		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
-	}, nil
+
+	runScheduler := vars.get("RUN_SCHEDULER", "separate")
+	if runScheduler != "separate" && runScheduler != "embedded" {
+		return nil, fmt.Errorf("invalid RUN_SCHEDULER %q: must be separate or embedded", runScheduler)
+	}
+
+	jobs, err := loadJobs(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		ServerPort:  vars.getInt("SERVER_PORT", 8080),
+		ServerHost:  vars.get("SERVER_HOST", ""),
+		MetricsPort: vars.getInt("METRICS_PORT", 9091),
+
+		APIMetricsPort: vars.getInt("API_METRICS_PORT", 9090),
+		DatabaseURL: vars.get("DATABASE_URL", "sqlite3://tasks.db"),
+		LogLevel:    vars.get("LOG_LEVEL", "info"),
+		JWTSecret:   vars.get("JWT_SECRET", ""),
+
+		LogFormat:     vars.get("LOG_FORMAT", "json"),
+		LogOutput:     vars.get("LOG_OUTPUT", "stdout"),
+		LogMaxSizeMB:  vars.getInt("LOG_MAX_SIZE_MB", 100),
+		LogMaxBackups: vars.getInt("LOG_MAX_BACKUPS", 5),
+
+		TracingExporter: vars.get("TRACING_EXPORTER", "none"),
+		TracingOutput:   vars.get("TRACING_OUTPUT", "stdout"),
+
+		StorageBackend:         vars.get("STORAGE_BACKEND", "local"),
+		StorageLocalDir:        vars.get("STORAGE_LOCAL_DIR", "data/attachments"),
+		S3Endpoint:             vars.get("S3_ENDPOINT", "localhost:9000"),
+		S3Region:               vars.get("S3_REGION", "us-east-1"),
+		S3Bucket:               vars.get("S3_BUCKET", "attachments"),
+		S3AccessKey:            vars.get("S3_ACCESS_KEY", ""),
+		S3SecretKey:            vars.get("S3_SECRET_KEY", ""),
+		S3UseSSL:               vars.getBool("S3_USE_SSL", false),
+		AttachmentMaxBytes:     int64(vars.getInt("ATTACHMENT_MAX_BYTES", 10<<20)),
+		AttachmentAllowedTypes: vars.getList("ATTACHMENT_ALLOWED_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain,text/csv,application/zip"),
+
+		SMTPHost:     vars.get("SMTP_HOST", ""),
+		SMTPPort:     vars.getInt("SMTP_PORT", 25),
+		SMTPUsername: vars.get("SMTP_USERNAME", ""),
+		SMTPPassword: vars.get("SMTP_PASSWORD", ""),
+		SMTPFrom:     vars.get("SMTP_FROM", "tasks@localhost"),
+
+		EscalateAssigneeAfterHours: vars.getInt("ESCALATE_ASSIGNEE_AFTER_HOURS", 24),
+		EscalateOwnerAfterHours:    vars.getInt("ESCALATE_OWNER_AFTER_HOURS", 72),
+		EscalateBumpPriority:       vars.getBool("ESCALATE_BUMP_PRIORITY", false),
+
+		RunScheduler:      runScheduler,
+		SchedulerLockTTL:  vars.getPositiveInt("SCHEDULER_LOCK_TTL", 30),
+		SchedulerTimezone: location,
+		Jobs:              jobs,
+
+		SchedulerShutdownTimeout: vars.getPositiveInt("SCHEDULER_SHUTDOWN_TIMEOUT", 30),
+
+		AdminUsers: vars.getList("ADMIN_USERS", ""),
+
+		QueueWorkers:           vars.getPositiveInt("QUEUE_WORKERS", 4),
+		QueueMaxAttempts:       vars.getInt("QUEUE_MAX_ATTEMPTS", 5),
+		QueueVisibilityTimeout: vars.getPositiveInt("QUEUE_VISIBILITY_TIMEOUT", 60),
+
+		RequireChecklistComplete: vars.getBool("REQUIRE_CHECKLIST_COMPLETE", false),
+	}
+	if vars.err != nil {
+		return nil, vars.err
+	}
+
+	return cfg, nil
+}
+
+// loadJobs reads the job schedules and rejects malformed cron expressions
+func loadJobs(vars *envVars) (map[string]JobConfig, error) {
+	jobs := map[string]JobConfig{}
+	for name, schedule := range defaultJobSchedules {
+		prefix := "JOB_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+		job := JobConfig{
+			Schedule: strings.TrimSpace(vars.get(prefix+"_SCHEDULE", schedule)),
+			Enabled:  vars.getBool(prefix+"_ENABLED", true),
+		}
+		if _, err := cron.ParseStandard(job.Schedule); err != nil {
+			return nil, fmt.Errorf("invalid %s_SCHEDULE %q: %w", prefix, job.Schedule, err)
+		}
+
+		jobs[name] = job
+	}
+	return jobs, nil
+}
+
+// envVars reads typed settings from the environment, falling back to the
+// configuration file. A malformed value falls back to the default and is
+// kept in err, so that Load can report the first one.
+type envVars struct {
+	file map[string]string
+	err  error
 }
 
-func getEnv(key, defaultValue string) string {
+func (v *envVars) get(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
+	if value, exists := v.file[key]; exists {
+		return value
+	}
 	return defaultValue
 }
+
+func (v *envVars) getInt(key string, defaultValue int) int {
+	raw := strings.TrimSpace(v.get(key, ""))
+	if raw == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		v.fail(fmt.Errorf("invalid %s %q: must be an integer", key, raw))
+		return defaultValue
+	}
+	return value
+}
+
+// getPositiveInt reads an integer that must be greater than zero, such as a
+// duration or a worker count
+func (v *envVars) getPositiveInt(key string, defaultValue int) int {
+	value := v.getInt(key, defaultValue)
+	if value <= 0 {
+		v.fail(fmt.Errorf("invalid %s %q: must be a positive integer", key, strings.TrimSpace(v.get(key, ""))))
+		return defaultValue
+	}
+	return value
+}
+
+func (v *envVars) getBool(key string, defaultValue bool) bool {
+	raw := strings.TrimSpace(v.get(key, ""))
+	if raw == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		v.fail(fmt.Errorf("invalid %s %q: must be true or false", key, raw))
+		return defaultValue
+	}
+	return value
+}
+
+func (v *envVars) fail(err error) {
+	if v.err == nil {
+		v.err = err
+	}
+}
+
+func (v *envVars) getList(key, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(v.get(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
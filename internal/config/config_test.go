@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadReadsNumbersAndFlags(t *testing.T) {
+	t.Setenv("QUEUE_WORKERS", " 8 ")
+	t.Setenv("ESCALATE_BUMP_PRIORITY", "true")
+	t.Setenv("JOB_SEND_DIGESTS_ENABLED", "0")
+	t.Setenv("SMTP_PORT", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.QueueWorkers != 8 || !cfg.EscalateBumpPriority || cfg.Jobs["send-digests"].Enabled || cfg.SMTPPort != 25 {
+		t.Errorf("unexpected config: workers %d, bump %v, digests enabled %v, SMTP port %d",
+			cfg.QueueWorkers, cfg.EscalateBumpPriority, cfg.Jobs["send-digests"].Enabled, cfg.SMTPPort)
+	}
+}
+
+func TestLoadRejectsMalformedValues(t *testing.T) {
+	tests := map[string]string{
+		"SERVER_PORT":                   "80a",
+		"QUEUE_WORKERS":                 "four",
+		"ATTACHMENT_MAX_BYTES":          "10MB",
+		"ESCALATE_BUMP_PRIORITY":        "yes",
+		"JOB_CLEANUP_OLD_TASKS_ENABLED": "on",
+	}
+
+	for key, value := range tests {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("Load with %s=%q = %v, want an error naming %s", key, value, err, key)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsNonPositiveValues(t *testing.T) {
+	for _, key := range []string{"SCHEDULER_LOCK_TTL", "SCHEDULER_SHUTDOWN_TIMEOUT", "QUEUE_WORKERS", "QUEUE_VISIBILITY_TIMEOUT"} {
+		for _, value := range []string{"0", "-5"} {
+			t.Run(key+"="+value, func(t *testing.T) {
+				t.Setenv(key, value)
+
+				_, err := Load()
+				if err == nil || !strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), "positive") {
+					t.Errorf("Load with %s=%q = %v, want an error naming %s", key, value, err, key)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadReadsConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.conf")
+	content := "# Scheduler\nJOB_SEND_DIGESTS_SCHEDULE=\"0 7 * * *\"\nJOB_CLEANUP_OLD_TASKS_ENABLED=false\nQUEUE_WORKERS=2\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing the config file failed: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	// The environment takes precedence over the file
+	t.Setenv("QUEUE_WORKERS", "6")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.Jobs["send-digests"].Schedule; got != "0 7 * * *" {
+		t.Errorf("send-digests schedule = %q, want the one from the file", got)
+	}
+	if cfg.Jobs["cleanup-old-tasks"].Enabled {
+		t.Error("cleanup-old-tasks is enabled, want it disabled by the file")
+	}
+	if cfg.QueueWorkers != 6 {
+		t.Errorf("queue workers = %d, want 6 from the environment", cfg.QueueWorkers)
+	}
+
+	// Values in the file are validated like environment variables
+	if err := os.WriteFile(path, []byte("JOB_SEND_DIGESTS_SCHEDULE=daily\n"), 0o600); err != nil {
+		t.Fatalf("writing the config file failed: %v", err)
+	}
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JOB_SEND_DIGESTS_SCHEDULE") {
+		t.Errorf("Load with a malformed schedule in the file = %v, want an error naming it", err)
+	}
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.conf"))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "CONFIG_FILE") {
+		t.Errorf("Load with a missing config file = %v, want an error naming CONFIG_FILE", err)
+	}
+}
@@ -0,0 +1,99 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// digestSection is a titled list of tasks inside a digest
+type digestSection struct {
+	Title string
+	Tasks []repository.Task
+}
+
+var digestFuncs = map[string]interface{}{
+	"section": func(title string, tasks []repository.Task) digestSection {
+		return digestSection{Title: title, Tasks: tasks}
+	},
+}
+
+var (
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(digestFuncs).ParseFS(templates, "templates/digest.html"))
+	digestText = template.Must(template.New("digest.txt").Funcs(digestFuncs).ParseFS(templates, "templates/digest.txt"))
+)
+
+// SendDigests emails the digests that are due. Users with nothing to report
+// get no email; their digest still counts as sent. It returns the number of
+// emails sent. When ctx is cancelled it stops between digests; the remaining
+// ones go out on the next run.
+func SendDigests(ctx context.Context, service *services.DigestService, mailer notify.Mailer, log *logger.Logger) (int, error) {
+	now := time.Now()
+
+	digests, err := service.Due(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("build digests: %w", err)
+	}
+
+	sent := 0
+	for _, digest := range digests {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		if !digest.Empty() {
+			email, err := renderDigest(&digest)
+			if err != nil {
+				log.Error("Failed to render digest", err, "user_id", digest.User.ID)
+				continue
+			}
+			if err := mailer.Send(*email); err != nil {
+				// Not marked as sent, so the next run tries again
+				log.Error("Failed to send digest", err, "user_id", digest.User.ID)
+				continue
+			}
+			sent++
+		}
+
+		if err := service.MarkSent(digest.User.ID, now); err != nil {
+			log.Error("Failed to record digest", err, "user_id", digest.User.ID)
+		}
+	}
+
+	return sent, nil
+}
+
+// renderDigest turns a digest into an email with plain text and HTML bodies
+func renderDigest(digest *services.Digest) (*notify.Email, error) {
+	var text, html bytes.Buffer
+	if err := digestText.Execute(&text, digest); err != nil {
+		return nil, err
+	}
+	if err := digestHTML.Execute(&html, digest); err != nil {
+		return nil, err
+	}
+
+	subject := "Your daily task digest"
+	if digest.Frequency == services.DigestWeekly {
+		subject = "Your weekly task digest"
+	}
+
+	return &notify.Email{
+		To:      digest.User.Email,
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,316 @@
+package cron
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+func TestSendDigests(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	repos, log := newDigestRepos(t)
+	user := createDigestUser(t, repos, "alice", location)
+
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	createDueTasks(t, repos, user, map[string]time.Time{
+		"Overdue report": today.Add(-time.Hour),
+		"Evening call":   now.Add(tomorrow.Sub(now) / 2),
+		"Planning":       tomorrow.AddDate(0, 0, 2).Add(10 * time.Hour),
+		"Offsite":        today.AddDate(0, 0, 30),
+	})
+
+	catcher := startSMTPCatcher(t)
+	host, port := catcher.address()
+	mailer := notify.NewSMTPMailer(host, port, "", "", "digest@example.com")
+	service := services.NewDigestService(repos)
+
+	sent, err := SendDigests(context.Background(), service, mailer, log)
+	if err != nil || sent != 1 {
+		t.Fatalf("SendDigests = %d, %v; want 1 email", sent, err)
+	}
+
+	text := catcher.text(t)
+	checkDigestSections(t, text, map[string][]string{
+		"Overdue":       {"Overdue report"},
+		"Due today":     {"Evening call"},
+		"Due this week": {"Planning"},
+	})
+	if strings.Contains(text, "Offsite") {
+		t.Errorf("a task due in a month is in the digest:\n%s", text)
+	}
+
+	// The digest is recorded as sent
+	sent, err = SendDigests(context.Background(), service, mailer, log)
+	if err != nil || sent != 0 {
+		t.Fatalf("second SendDigests = %d, %v; want no email", sent, err)
+	}
+}
+
+func TestSendDigestsDateOnlyDueDates(t *testing.T) {
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	repos, log := newDigestRepos(t)
+	user := createDigestUser(t, repos, "bob", location)
+
+	// Due dates without a time are stored at midnight UTC, which is the
+	// previous evening in Los Angeles
+	now := time.Now().In(location)
+	date := func(days int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, time.UTC)
+	}
+	createDueTasks(t, repos, user, map[string]time.Time{
+		"Tax form":      date(-1),
+		"Dentist":       date(0),
+		"Code review":   date(1),
+		"Annual review": date(20),
+	})
+
+	catcher := startSMTPCatcher(t)
+	host, port := catcher.address()
+	mailer := notify.NewSMTPMailer(host, port, "", "", "digest@example.com")
+
+	sent, err := SendDigests(context.Background(), services.NewDigestService(repos), mailer, log)
+	if err != nil || sent != 1 {
+		t.Fatalf("SendDigests = %d, %v; want 1 email", sent, err)
+	}
+
+	text := catcher.text(t)
+	checkDigestSections(t, text, map[string][]string{
+		"Overdue":       {"Tax form"},
+		"Due today":     {"Dentist"},
+		"Due this week": {"Code review"},
+	})
+	if want := "Dentist (due " + now.Format("Jan 2") + ")"; !strings.Contains(text, want) {
+		t.Errorf("the digest does not contain %q:\n%s", want, text)
+	}
+	if strings.Contains(text, "Annual review") {
+		t.Errorf("a task due in 20 days is in the digest:\n%s", text)
+	}
+}
+
+// newDigestRepos returns repositories on an empty database
+func newDigestRepos(t *testing.T) (*repository.Repositories, *logger.Logger) {
+	t.Helper()
+
+	db, err := repository.NewDatabase("sqlite3://" + filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log, err := logger.NewLogger(logger.Options{Level: "error", Format: "text", Output: "stderr"})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+
+	return repository.NewRepositories(db), log
+}
+
+// createDigestUser creates a user whose daily digest at local midnight is
+// always due
+func createDigestUser(t *testing.T, repos *repository.Repositories, username string, location *time.Location) *repository.User {
+	t.Helper()
+
+	user, err := repos.Users.Create(&repository.User{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "-",
+		CreatedAt:    time.Now().AddDate(0, 0, -30),
+	})
+	if err != nil {
+		t.Fatalf("creating the user failed: %v", err)
+	}
+
+	_, err = repos.Digests.Save(&repository.DigestSettings{
+		UserID:    user.ID,
+		Frequency: services.DigestDaily,
+		Hour:      0,
+		Weekday:   "monday",
+		Timezone:  location.String(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("saving the digest settings failed: %v", err)
+	}
+
+	return user
+}
+
+// createDueTasks creates open tasks assigned to user, keyed by title
+func createDueTasks(t *testing.T, repos *repository.Repositories, user *repository.User, tasks map[string]time.Time) {
+	t.Helper()
+
+	for title, due := range tasks {
+		due := due.UTC()
+		_, err := repos.Tasks.Create(context.Background(), &repository.Task{
+			Title:      title,
+			Priority:   services.PriorityNormal,
+			Status:     services.StatusTodo,
+			CreatorID:  &user.ID,
+			AssigneeID: &user.ID,
+			DueDate:    &due,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("creating task %q failed: %v", title, err)
+		}
+	}
+}
+
+// checkDigestSections checks that each section lists exactly the given tasks
+func checkDigestSections(t *testing.T, text string, sections map[string][]string) {
+	t.Helper()
+
+	for section, want := range sections {
+		got := digestSectionOf(text, section)
+		for _, title := range want {
+			if !strings.Contains(got, title) {
+				t.Errorf("section %q is missing %q:\n%s", section, title, text)
+			}
+		}
+		if strings.Count(got, "  - #") != len(want) {
+			t.Errorf("section %q lists %d tasks, want %d:\n%s", section, strings.Count(got, "  - #"), len(want), text)
+		}
+	}
+}
+
+// digestSectionOf returns the task lines of a section of a digest's text body
+func digestSectionOf(text, title string) string {
+	start := strings.Index(text, "\n"+title+":\n")
+	if start < 0 {
+		return ""
+	}
+	section := text[start+len(title)+3:]
+	if end := strings.Index(section, "\n\n"); end >= 0 {
+		section = section[:end]
+	}
+	return section
+}
+
+// smtpCatcher is a minimal SMTP server that keeps the messages it receives
+type smtpCatcher struct {
+	listener net.Listener
+	messages chan string
+}
+
+func startSMTPCatcher(t *testing.T) *smtpCatcher {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	c := &smtpCatcher{listener: listener, messages: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go c.serve(conn)
+		}
+	}()
+
+	return c
+}
+
+func (c *smtpCatcher) address() (string, int) {
+	addr := c.listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func (c *smtpCatcher) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch command := strings.ToUpper(strings.Fields(line + " ")[0]); command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			c.messages <- string(data)
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// text waits for the next message and returns its decoded plain text body
+func (c *smtpCatcher) text(t *testing.T) string {
+	t.Helper()
+
+	var raw string
+	select {
+	case raw = <-c.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email was received")
+	}
+
+	message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		t.Fatalf("reading the email failed: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parsing the content type failed: %v", err)
+	}
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatalf("the email has no plain text part: %v", err)
+		}
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain") {
+			body, err := io.ReadAll(part)
+			if err != nil {
+				t.Fatalf("reading the plain text part failed: %v", err)
+			}
+			return string(body)
+		}
+	}
+}
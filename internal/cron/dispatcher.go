@@ -0,0 +1,75 @@
+package cron
+
+import (
+	"context"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// maxReminderIdle bounds how long the dispatcher sleeps. Reminders are
+// created by the API process, so the dispatcher looks for new ones at least
+// this often.
+const maxReminderIdle = time.Minute
+
+// ReminderDispatcher delivers task reminders at their scheduled time
+type ReminderDispatcher struct {
+	service *services.ReminderService
+	logger  *logger.Logger
+}
+
+// NewReminderDispatcher creates a new ReminderDispatcher
+func NewReminderDispatcher(service *services.ReminderService, logger *logger.Logger) *ReminderDispatcher {
+	return &ReminderDispatcher{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Run delivers due reminders and then sleeps until the next one is due,
+// until ctx is done
+func (d *ReminderDispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		wait := d.dispatch(ctx)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatch delivers the due reminders and returns how long to wait for the next one
+func (d *ReminderDispatcher) dispatch(ctx context.Context) time.Duration {
+	sent, err := d.service.Dispatch(ctx, time.Now())
+	if sent > 0 {
+		d.logger.Info("Sent reminders", "count", sent)
+	}
+	if ctx.Err() != nil {
+		// Shutting down
+		return 0
+	}
+	if err != nil {
+		d.logger.Error("Failed to dispatch reminders", err)
+		return maxReminderIdle
+	}
+
+	next, err := d.service.NextAt(ctx)
+	if err != nil {
+		d.logger.Error("Failed to get the next reminder", err)
+		return maxReminderIdle
+	}
+
+	if next == nil {
+		return maxReminderIdle
+	}
+	if wait := time.Until(*next); wait < maxReminderIdle {
+		// Reminders that became due meanwhile are picked up right away
+		return max(wait, 0)
+	}
+	return maxReminderIdle
+}
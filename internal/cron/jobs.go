@@ -1,38 +1,81 @@
 package cron
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"golang_task_manager_folder_structure/internal/logger"
-	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/services"
 )
 
-// TaskReminder checks for upcoming tasks and potentially sends reminders
-func TaskReminder(repo *repository.TaskRepository, log *logger.Logger) {
-	log.Info("Running task reminder job")
+// WakeSnoozedTasks makes snoozed tasks whose time has come available again and
+// tells their assignees (or, for unassigned tasks, creators) about it. It
+// returns the number of tasks woken. When ctx is cancelled it stops waking
+// tasks, but the tasks already woken are still announced.
+func WakeSnoozedTasks(ctx context.Context, service *services.TaskService, notifier notify.Notifier, log *logger.Logger) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	tasks, err := service.WakeUp(ctx, policy.System, time.Now())
+	for _, task := range tasks {
+		recipient := task.AssigneeID
+		if recipient == nil {
+			recipient = task.CreatorID
+		}
+		if recipient == nil {
+			log.Info("Task is available again", "task_id", task.ID, "title", task.Title)
+			continue
+		}
+
+		err := notifier.Notify(notify.Notification{
+			UserID:  *recipient,
+			TaskID:  &task.ID,
+			Subject: fmt.Sprintf("Task #%d is available", task.ID),
+			Body:    task.Title,
+		})
+		if err != nil {
+			log.Error("Failed to announce task", err, "task_id", task.ID)
+		}
+	}
 
-	// Get all incomplete tasks
-	tasks, err := repo.FindAll()
 	if err != nil {
-		log.Error("Failed to get tasks for reminder", err)
-		return
+		return len(tasks), fmt.Errorf("wake snoozed tasks: %w", err)
 	}
+	return len(tasks), nil
+}
 
-	tomorrow := time.Now().AddDate(0, 0, 1)
+// EscalateOverdueTasks notifies assignees and project owners about tasks that
+// have been overdue for too long. It returns the number of escalation steps
+// taken; when ctx is cancelled it stops before the next task.
+func EscalateOverdueTasks(ctx context.Context, service *services.EscalationService, log *logger.Logger) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 
-	for _, task := range tasks {
-		if !task.Completed && task.DueDate != nil && task.DueDate.Before(tomorrow) {
-			log.Info("Task #%d '%s' is due soon (%s)", task.ID, task.Title, task.DueDate.Format("2006-01-02"))
-			// In a real application, we would send an email or notification here
-		}
+	taken, err := service.Escalate(ctx, time.Now())
+	if taken > 0 {
+		log.Info("Took escalation steps for overdue tasks", "steps", taken)
 	}
+	if err != nil {
+		return taken, fmt.Errorf("escalate overdue tasks: %w", err)
+	}
+	return taken, nil
 }
 
 // CleanupOldTasks archives or removes old completed tasks
-func CleanupOldTasks(repo *repository.TaskRepository, log *logger.Logger) {
+func CleanupOldTasks(ctx context.Context, service *services.TaskService, log *logger.Logger) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
 	log.Info("Running cleanup job for old tasks")
 
 	// In a real application, we would archive or delete old tasks
 	// This is just a placeholder
 	log.Info("Cleanup job completed")
+	return 0, nil
 }
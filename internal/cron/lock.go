@@ -0,0 +1,141 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Lock is held by at most one scheduler instance at a time
+type Lock interface {
+	// TryAcquire takes or renews the lock and reports whether this instance holds it
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release gives up the lock
+	Release(ctx context.Context) error
+	// Lease identifies the lease the lock is held through, so that writes
+	// can check they are made by its current holder
+	Lease() repository.Lease
+}
+
+// minRenewInterval bounds how often the lock is renewed for very short TTLs
+const minRenewInterval = 100 * time.Millisecond
+
+// LeaseLock is a Lock backed by a lease row that expires unless renewed
+// within its TTL
+type LeaseLock struct {
+	repo  *repository.LeaseRepository
+	name  string
+	owner string
+	ttl   time.Duration
+}
+
+// NewLeaseLock creates a new LeaseLock owned by this process
+func NewLeaseLock(repo *repository.LeaseRepository, name string, ttl time.Duration) *LeaseLock {
+	host, _ := os.Hostname()
+
+	return &LeaseLock{
+		repo:  repo,
+		name:  name,
+		owner: fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano()),
+		ttl:   ttl,
+	}
+}
+
+// TryAcquire takes the lease, or renews it when this instance already holds it
+func (l *LeaseLock) TryAcquire(ctx context.Context) (bool, error) {
+	return l.repo.Acquire(l.name, l.owner, time.Now(), l.ttl)
+}
+
+// Release deletes the lease so that another instance can take over right away
+func (l *LeaseLock) Release(ctx context.Context) error {
+	return l.repo.Release(l.name, l.owner)
+}
+
+// Lease returns the name of the lease and this instance's owner ID
+func (l *LeaseLock) Lease() repository.Lease {
+	return repository.Lease{Name: l.name, Owner: l.owner}
+}
+
+// Leader keeps trying to hold a Lock so that only one of several scheduler
+// instances runs the jobs. It renews the lock three times per TTL and stops
+// leading when a renewal has not succeeded within the TTL. Since that relies
+// on this instance's clock, job runs are also fenced: each run is recorded
+// only after the database confirms the lease is still held (see Lease).
+type Leader struct {
+	lock    Lock
+	ttl     time.Duration
+	logger  *logger.Logger
+	renewed atomic.Int64
+}
+
+// NewLeader creates a new Leader
+func NewLeader(lock Lock, ttl time.Duration, logger *logger.Logger) *Leader {
+	return &Leader{
+		lock:   lock,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Lease identifies the lease job runs are fenced with
+func (l *Leader) Lease() repository.Lease {
+	return l.lock.Lease()
+}
+
+// IsLeader reports whether this instance currently holds the lock
+func (l *Leader) IsLeader() bool {
+	renewed := l.renewed.Load()
+	return renewed != 0 && time.Since(time.Unix(0, renewed)) < l.ttl
+}
+
+// Run renews the lock until ctx is done and then releases it
+func (l *Leader) Run(ctx context.Context) {
+	interval := l.ttl / 3
+	if interval < minRenewInterval {
+		interval = minRenewInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if l.IsLeader() {
+				if err := l.lock.Release(context.Background()); err != nil {
+					l.logger.Error("Failed to release the scheduler lock", err)
+				}
+			}
+			return
+		case <-ticker.C:
+			l.Renew(ctx)
+		}
+	}
+}
+
+// Renew tries to take or renew the lock once
+func (l *Leader) Renew(ctx context.Context) {
+	was := l.IsLeader()
+	start := time.Now()
+
+	acquired, err := l.lock.TryAcquire(ctx)
+	if err != nil {
+		l.logger.Error("Failed to renew the scheduler lock", err)
+	}
+
+	if acquired {
+		l.renewed.Store(start.UnixNano())
+	} else {
+		l.renewed.Store(0)
+	}
+
+	if acquired && !was {
+		l.logger.Info("This instance now runs the scheduled jobs")
+	} else if !acquired && was {
+		l.logger.Info("Another instance now runs the scheduled jobs")
+	}
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+func TestJobRunsAreFencedByTheLease(t *testing.T) {
+	db, err := repository.NewDatabase("sqlite3://" + filepath.Join(t.TempDir(), "lock.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repos := repository.NewRepositories(db)
+	jobs := services.NewJobService(repos)
+
+	ttl := 200 * time.Millisecond
+	former := NewLeaseLock(repos.Leases, "scheduler", ttl)
+	current := NewLeaseLock(repos.Leases, "scheduler", ttl)
+
+	if acquired, err := former.TryAcquire(context.Background()); err != nil || !acquired {
+		t.Fatalf("first TryAcquire = %v, %v; want the lease", acquired, err)
+	}
+
+	lease := former.Lease()
+	if _, err := jobs.Start(JobSendDigests, repository.TriggerSchedule, "former", &lease); err != nil {
+		t.Fatalf("Start by the holder failed: %v", err)
+	}
+
+	// The former leader stops renewing, e.g. because it stalled, and another
+	// instance takes over once the lease expired
+	time.Sleep(ttl)
+	if acquired, err := current.TryAcquire(context.Background()); err != nil || !acquired {
+		t.Fatalf("TryAcquire after expiry = %v, %v; want the lease", acquired, err)
+	}
+
+	if _, err := jobs.Start(JobSendDigests, repository.TriggerSchedule, "former", &lease); !errors.Is(err, repository.ErrLeaseLost) {
+		t.Errorf("Start by the former holder = %v, want ErrLeaseLost", err)
+	}
+
+	lease = current.Lease()
+	if _, err := jobs.Start(JobSendDigests, repository.TriggerSchedule, "current", &lease); err != nil {
+		t.Errorf("Start by the new holder failed: %v", err)
+	}
+
+	runs, err := repos.Jobs.FindRuns(JobSendDigests, 10)
+	if err != nil {
+		t.Fatalf("FindRuns failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Instance != "current" || runs[1].Instance != "former" {
+		t.Errorf("recorded runs %+v, want one by each holder while it held the lease", runs)
+	}
+}
@@ -1,44 +1,388 @@
 package cron
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
 	"time"
 
+	"golang_task_manager_folder_structure/internal/config"
 	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
+	"golang_task_manager_folder_structure/internal/notify"
 	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
 
 	"github.com/go-co-op/gocron"
+	cronexpr "github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("golang_task_manager_folder_structure/internal/cron")
+
+// requestPollInterval is how often the leader looks for manual run requests
+const requestPollInterval = 5 * time.Second
+
+// Names of the scheduled jobs, as used in the configuration and the admin API
+const (
+	JobWakeSnoozedTasks     = "wake-snoozed-tasks"
+	JobSendDigests          = "send-digests"
+	JobEscalateOverdueTasks = "escalate-overdue-tasks"
+	JobCleanupOldTasks      = "cleanup-old-tasks"
+)
+
+// Dependencies holds the services the scheduled jobs work with
+type Dependencies struct {
+	Tasks       *services.TaskService
+	Reminders   *services.ReminderService
+	Digests     *services.DigestService
+	Escalations *services.EscalationService
+	Jobs        *services.JobService
+	Queue       *services.QueueService
+	// Metrics records job runs when set
+	Metrics  *metrics.Jobs
+	Notifier notify.Notifier
+	// Mailer sends digest emails; digests are disabled when it is nil
+	Mailer notify.Mailer
+}
+
+// Settings configures when the jobs run
+type Settings struct {
+	// Location is the time zone schedules are interpreted in
+	Location *time.Location
+	// Jobs holds the schedule of every job by name
+	Jobs map[string]config.JobConfig
+	// ShutdownTimeout is how long Start waits for running jobs once its
+	// context is done before cancelling them
+	ShutdownTimeout time.Duration
+	// Workers is how many queued jobs this instance runs at once
+	Workers int
+}
+
+// job is a recurring job known to the scheduler
+type job struct {
+	name     string
+	schedule string
+	// run does the work and returns the number of items it processed
+	run   func(ctx context.Context) (int, error)
+	entry *gocron.Job
+	// cron is the parsed schedule, nil for disabled jobs
+	cron cronexpr.Schedule
+}
+
 // Scheduler runs recurring jobs
 type Scheduler struct {
 	scheduler *gocron.Scheduler
-	repo      *repository.TaskRepository
+	deps      Dependencies
+	reminders *ReminderDispatcher
+	workers   *WorkerPool
+	leader    *Leader
 	logger    *logger.Logger
+	jobs      []*job
+	instance  string
+	location  *time.Location
+	timeout   time.Duration
+	// runs is the context jobs run with, cancelled when they take too long
+	// to finish on shutdown
+	runs       context.Context
+	cancelRuns context.CancelFunc
+	// caughtUp is set once missed runs were made up for
+	caughtUp bool
 }
 
-// NewScheduler creates a new scheduler
-func NewScheduler(repo *repository.TaskRepository, logger *logger.Logger) *Scheduler {
-	s := gocron.NewScheduler(time.UTC)
+// NewScheduler creates a new scheduler and schedules the jobs. With several
+// instances, the leader decides which one runs the jobs; without a leader
+// every job runs here.
+func NewScheduler(deps Dependencies, settings Settings, leader *Leader, logger *logger.Logger) (*Scheduler, error) {
+	host, _ := os.Hostname()
+	instance := fmt.Sprintf("%s-%d", host, os.Getpid())
+	runs, cancelRuns := context.WithCancel(context.Background())
 
-	return &Scheduler{
-		scheduler: s,
-		repo:      repo,
-		logger:    logger,
+	s := &Scheduler{
+		scheduler: gocron.NewScheduler(settings.Location),
+		deps:      deps,
+		reminders: NewReminderDispatcher(deps.Reminders, logger),
+		workers: NewWorkerPool(deps.Queue, map[string]Handler{
+			services.QueueNotification: NotificationHandler(deps.Notifier),
+		}, settings.Workers, instance, logger),
+		leader:     leader,
+		logger:     logger,
+		instance:   instance,
+		location:   settings.Location,
+		timeout:    settings.ShutdownTimeout,
+		runs:       runs,
+		cancelRuns: cancelRuns,
 	}
-}
 
-// Start begins the scheduler
-func (s *Scheduler) Start() {
-	// Schedule task reminder job to run daily at 9 AM
-	s.scheduler.Every(1).Day().At("09:00").Do(func() {
-		TaskReminder(s.repo, s.logger)
+	err := s.add(settings, JobWakeSnoozedTasks, func(ctx context.Context) (int, error) {
+		return WakeSnoozedTasks(ctx, s.deps.Tasks, s.deps.Notifier, s.logger)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Digests need a mailer
+	if s.deps.Mailer != nil {
+		err := s.add(settings, JobSendDigests, func(ctx context.Context) (int, error) {
+			return SendDigests(ctx, s.deps.Digests, s.deps.Mailer, s.logger)
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		s.logger.Info("SMTP is not configured, digest emails are disabled")
+	}
+
+	err = s.add(settings, JobEscalateOverdueTasks, func(ctx context.Context) (int, error) {
+		return EscalateOverdueTasks(ctx, s.deps.Escalations, s.logger)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Schedule cleanup job to run weekly on Sunday at midnight
-	s.scheduler.Every(1).Week().Sunday().At("00:00").Do(func() {
-		CleanupOldTasks(s.repo, s.logger)
+	err = s.add(settings, JobCleanupOldTasks, func(ctx context.Context) (int, error) {
+		return CleanupOldTasks(ctx, s.deps.Tasks, s.logger)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Run jobs triggered through the admin API
+	if _, err := s.scheduler.Every(requestPollInterval).SingletonMode().Do(s.runRequested); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Start runs the jobs until ctx is done. It then waits up to the shutdown
+// timeout for running jobs, cancels those still running, and gives up the
+// leadership.
+func (s *Scheduler) Start(ctx context.Context) {
+	// The lock is held until the running jobs are done, so that no other
+	// instance starts them again meanwhile
+	leading, stopLeading := context.WithCancel(context.Background())
+	led := make(chan struct{})
+	if s.leader != nil {
+		// Find out whether this instance leads before the first jobs run
+		s.leader.Renew(leading)
+		go func() {
+			s.leader.Run(leading)
+			close(led)
+		}()
+	} else {
+		close(led)
+	}
+
+	// Deliver task reminders as they become due. Reminders are claimed before
+	// they are sent, so the dispatcher runs on every instance.
+	go s.reminders.Run(ctx)
+
+	// Work off the background job queue. Like reminders, queued jobs are
+	// claimed, so every instance runs workers.
+	working := make(chan struct{})
+	go func() {
+		s.workers.Run(ctx, s.runs)
+		close(working)
+	}()
+
+	// Start scheduler and publish the jobs with their first run times
+	s.scheduler.StartAsync()
+	for _, job := range s.jobs {
+		s.register(job)
+	}
+
+	<-ctx.Done()
+	s.stop(working)
+	stopLeading()
+	<-led
+}
+
+// stop stops scheduling jobs and waits for the running ones and the queue
+// workers, cancelling them when they do not finish within the shutdown timeout
+func (s *Scheduler) stop(working <-chan struct{}) {
+	s.logger.Info("Stopping the scheduler, waiting for running jobs", "timeout", s.timeout.String())
+
+	stopped := make(chan struct{})
+	go func() {
+		s.scheduler.Stop()
+		<-working
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		s.logger.Info("Scheduler stopped")
+	case <-timer.C:
+		s.cancelRuns()
+		s.logger.Warn("Running jobs did not finish in time and were cancelled")
+	}
+}
+
+// add schedules a job as configured. Disabled jobs are only run on request.
+func (s *Scheduler) add(settings Settings, name string, run func(ctx context.Context) (int, error)) error {
+	cfg, ok := settings.Jobs[name]
+	if !ok {
+		return fmt.Errorf("no schedule configured for job %s", name)
+	}
+
+	job := &job{name: name, schedule: cfg.Schedule, run: run}
+	s.jobs = append(s.jobs, job)
+
+	if !cfg.Enabled {
+		job.schedule = "disabled"
+		s.logger.Info("Job is disabled", "job", name)
+		return nil
+	}
 
-	// Start scheduler
-	s.scheduler.StartBlocking()
+	schedule, err := cronexpr.ParseStandard(cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("schedule job %s: %w", name, err)
+	}
+
+	entry, err := s.scheduler.Cron(cfg.Schedule).Do(func() {
+		if s.leader != nil && !s.leader.IsLeader() {
+			return
+		}
+		s.execute(job, repository.TriggerSchedule)
+		s.register(job)
+	})
+	if err != nil {
+		return fmt.Errorf("schedule job %s: %w", name, err)
+	}
+
+	job.entry = entry
+	job.cron = schedule
+	return nil
+}
+
+// execute runs a job and records the run. A leading instance first checks
+// in the run's transaction that it still holds the lease, and skips the job
+// if another instance took over meanwhile.
+func (s *Scheduler) execute(job *job, trigger string) {
+	var lease *repository.Lease
+	if s.leader != nil {
+		held := s.leader.Lease()
+		lease = &held
+	}
+
+	run, err := s.deps.Jobs.Start(job.name, trigger, s.instance, lease)
+	if errors.Is(err, repository.ErrLeaseLost) {
+		s.logger.Warn("Skipping job, another instance holds the scheduler lock", "job", job.name, "trigger", trigger)
+		return
+	} else if err != nil {
+		s.logger.Error("Failed to record the start of a job", err, "job", job.name)
+	}
+
+	// Every run starts its own trace
+	ctx, span := tracer.Start(s.runs, "job "+job.name, trace.WithNewRoot(), trace.WithAttributes(
+		attribute.String("job.name", job.name),
+		attribute.String("job.trigger", trigger),
+	))
+
+	start := time.Now()
+	items, err := job.run(ctx)
+	if s.deps.Metrics != nil {
+		s.deps.Metrics.Observe(job.name, trigger, time.Since(start), err)
+	}
+	if err != nil {
+		s.logger.Error("Job failed", err, "job", job.name, "trigger", trigger)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(attribute.Int("job.items", items))
+	span.End()
+
+	if run != nil {
+		if err := s.deps.Jobs.Finish(run, items, err); err != nil {
+			s.logger.Error("Failed to record the end of a job", err, "job", job.name)
+		}
+	}
+}
+
+// register publishes a job's schedule and next run time
+func (s *Scheduler) register(job *job) {
+	var next time.Time
+	if job.entry != nil {
+		next = job.entry.NextRun()
+	}
+
+	if err := s.deps.Jobs.Register(job.name, job.schedule, next); err != nil {
+		s.logger.Error("Failed to register job", err, "job", job.name)
+	}
+}
+
+// runRequested runs the jobs that were triggered manually. The first time
+// this instance leads, it also makes up for missed runs.
+func (s *Scheduler) runRequested() {
+	if s.leader != nil && !s.leader.IsLeader() {
+		return
+	}
+
+	if !s.caughtUp {
+		s.catchUp()
+		s.caughtUp = true
+	}
+
+	names, err := s.deps.Jobs.ClaimRequested()
+	if err != nil {
+		s.logger.Error("Failed to claim manual job runs", err)
+	}
+
+	for _, name := range names {
+		job := s.find(name)
+		if job == nil {
+			s.logger.Warn("Ignoring manual run of unknown job", "job", name)
+			continue
+		}
+
+		s.logger.Info("Running job on request", "job", name)
+		s.execute(job, repository.TriggerManual)
+	}
+}
+
+// find returns the scheduled job with the given name
+func (s *Scheduler) find(name string) *job {
+	for _, job := range s.jobs {
+		if job.name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+// catchUp runs every enabled job once whose last scheduled time passed since
+// its last recorded run, e.g. because no instance was running at the time.
+// Jobs that never ran have nothing to make up for.
+func (s *Scheduler) catchUp() {
+	for _, job := range s.jobs {
+		if job.cron == nil {
+			continue
+		}
+
+		last, err := s.deps.Jobs.LastRun(job.name)
+		if err != nil {
+			s.logger.Error("Failed to get the last run of a job", err, "job", job.name)
+			continue
+		}
+		if last == nil {
+			continue
+		}
+
+		// Runs that are just due are left to the scheduler
+		missed := job.cron.Next(last.StartedAt.In(s.location))
+		if time.Since(missed) < requestPollInterval {
+			continue
+		}
+
+		s.logger.Info("Job missed its run, running it now", "job", job.name, "missed_at", missed.Format(time.RFC3339))
+		s.execute(job, repository.TriggerCatchUp)
+	}
 }
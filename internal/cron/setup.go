@@ -0,0 +1,65 @@
+package cron
+
+import (
+	"time"
+
+	"golang_task_manager_folder_structure/internal/config"
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/metrics"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+	"golang_task_manager_folder_structure/internal/storage"
+)
+
+// NewSchedulerFromConfig builds the services the jobs need on top of the
+// repositories and creates a scheduler that competes for leadership with the
+// other instances and records its runs in jobMetrics
+func NewSchedulerFromConfig(cfg *config.Config, repos *repository.Repositories, blobs storage.BlobStore, jobMetrics *metrics.Jobs, logger *logger.Logger) (*Scheduler, error) {
+	// Initialize services
+	notifier := notify.NewInboxNotifier(repos.Notifications, logger)
+	policy := policy.New(repos.Workspaces)
+	attachmentService := services.NewAttachmentService(repos, policy, blobs, services.AttachmentLimits{
+		MaxBytes:     cfg.AttachmentMaxBytes,
+		AllowedTypes: cfg.AttachmentAllowedTypes,
+	}, logger)
+	queue := services.NewQueueService(repos, services.QueueOptions{
+		MaxAttempts:       cfg.QueueMaxAttempts,
+		VisibilityTimeout: time.Duration(cfg.QueueVisibilityTimeout) * time.Second,
+	})
+	taskService := services.NewTaskService(repos, policy, queue, attachmentService, services.TaskOptions{
+		RequireChecklistComplete: cfg.RequireChecklistComplete,
+	}, logger)
+
+	// Digest emails need an SMTP server
+	var mailer notify.Mailer
+	if cfg.SMTPHost != "" {
+		mailer = notify.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+
+	// Elect the instance that runs the jobs when several are deployed
+	lockTTL := time.Duration(cfg.SchedulerLockTTL) * time.Second
+	leader := NewLeader(NewLeaseLock(repos.Leases, "scheduler", lockTTL), lockTTL, logger)
+
+	return NewScheduler(Dependencies{
+		Tasks:     taskService,
+		Reminders: services.NewReminderService(repos, policy, notifier),
+		Digests:   services.NewDigestService(repos),
+		Escalations: services.NewEscalationService(repos, notifier, services.EscalationPolicy{
+			AssigneeAfter: time.Duration(cfg.EscalateAssigneeAfterHours) * time.Hour,
+			OwnerAfter:    time.Duration(cfg.EscalateOwnerAfterHours) * time.Hour,
+			BumpPriority:  cfg.EscalateBumpPriority,
+		}),
+		Jobs:     services.NewJobService(repos),
+		Queue:    queue,
+		Metrics:  jobMetrics,
+		Notifier: notifier,
+		Mailer:   mailer,
+	}, Settings{
+		Location:        cfg.SchedulerTimezone,
+		Jobs:            cfg.Jobs,
+		ShutdownTimeout: time.Duration(cfg.SchedulerShutdownTimeout) * time.Second,
+		Workers:         cfg.QueueWorkers,
+	}, leader, logger)
+}
@@ -0,0 +1,109 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/services"
+)
+
+// queueIdle is how long a worker waits before looking for jobs again when
+// the queue is empty
+const queueIdle = time.Second
+
+// Handler runs a queued job of one kind
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// WorkerPool runs queued jobs with a fixed number of workers. Jobs are
+// claimed before they run, so pools on several instances share the queue.
+type WorkerPool struct {
+	queue    *services.QueueService
+	handlers map[string]Handler
+	workers  int
+	instance string
+	logger   *logger.Logger
+}
+
+// NewWorkerPool creates a new WorkerPool
+func NewWorkerPool(queue *services.QueueService, handlers map[string]Handler, workers int, instance string, logger *logger.Logger) *WorkerPool {
+	return &WorkerPool{
+		queue:    queue,
+		handlers: handlers,
+		workers:  max(workers, 1),
+		instance: instance,
+		logger:   logger,
+	}
+}
+
+// Run works off the queue until ctx is done and the running jobs are
+// finished. Jobs run with the jobs context.
+func (p *WorkerPool) Run(ctx, jobs context.Context) {
+	var wg sync.WaitGroup
+	for i := 1; i <= p.workers; i++ {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			p.work(ctx, jobs, worker)
+		}(fmt.Sprintf("%s/%d", p.instance, i))
+	}
+	wg.Wait()
+}
+
+// work claims and runs jobs one at a time until ctx is done
+func (p *WorkerPool) work(ctx, jobs context.Context, worker string) {
+	for ctx.Err() == nil {
+		job, err := p.queue.Claim(worker)
+		if err != nil {
+			p.logger.Error("Failed to claim a queued job", err)
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(queueIdle):
+			}
+			continue
+		}
+
+		p.run(jobs, job)
+	}
+}
+
+// run runs a claimed job and records the outcome
+func (p *WorkerPool) run(ctx context.Context, job *repository.QueueJob) {
+	err := fmt.Errorf("no handler for jobs of kind %q", job.Kind)
+	if handle, ok := p.handlers[job.Kind]; ok {
+		err = handle(ctx, job.Payload)
+	}
+
+	var held bool
+	if err == nil {
+		held, err = p.queue.Complete(job)
+	} else {
+		p.logger.Error("Queued job failed", err, "job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts, "max_attempts", job.MaxAttempts)
+		held, err = p.queue.Fail(job, err)
+	}
+
+	if err != nil {
+		p.logger.Error("Failed to record the outcome of a queued job", err, "job_id", job.ID)
+	} else if !held {
+		p.logger.Warn("Queued job ran past its visibility timeout and was handed to another worker", "job_id", job.ID)
+	}
+}
+
+// NotificationHandler delivers queued notifications
+func NotificationHandler(notifier notify.Notifier) Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var notification notify.Notification
+		if err := json.Unmarshal(payload, &notification); err != nil {
+			return err
+		}
+		return notifier.Notify(notification)
+	}
+}
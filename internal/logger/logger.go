@@ -1,60 +1,142 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
-	"time"
 )
 
-// Logger provides logging capabilities
+// LevelFatal is logged by Fatal before the program exits
+const LevelFatal = slog.LevelError + 4
+
+// Options configures a Logger
+type Options struct {
+	// Level is the lowest level logged: debug, info, warn or error
+	Level string
+	// Format is json or text
+	Format string
+	// Output is stdout, stderr or the path of a log file
+	Output string
+	// MaxSizeMB is the size at which a log file is rotated; 0 never rotates
+	MaxSizeMB int
+	// MaxBackups is how many rotated log files are kept
+	MaxBackups int
+}
+
+// Logger writes structured log records. Besides the message, records carry
+// key-value fields, e.g. logger.Info("Task woken", "task_id", task.ID).
 type Logger struct {
-	level  string
-	logger *log.Logger
+	slog *slog.Logger
 }
 
 // NewLogger creates a new logger instance
-func NewLogger(level string) *Logger {
-	return &Logger{
-		level:  strings.ToLower(level),
-		logger: log.New(os.Stdout, "", 0),
+func NewLogger(options Options) (*Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(options.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: must be debug, info, warn or error", options.Level)
 	}
-}
 
-// Info logs information messages
-func (l *Logger) Info(message string, args ...interface{}) {
-	l.log("INFO", message, args...)
+	output, err := openOutput(options)
+	if err != nil {
+		return nil, err
+	}
+
+	handlerOptions := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceLevel,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(options.Format) {
+	case "json":
+		handler = slog.NewJSONHandler(output, handlerOptions)
+	case "text":
+		handler = slog.NewTextHandler(output, handlerOptions)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be json or text", options.Format)
+	}
+
+	return &Logger{slog: slog.New(handler)}, nil
 }
 
-// Error logs error messages
-func (l *Logger) Error(message string, err error, args ...interface{}) {
+// openOutput returns the destination log records are written to
+func openOutput(options Options) (io.Writer, error) {
+	switch options.Output {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	file, err := openRotatingFile(options.Output, int64(options.MaxSizeMB)<<20, options.MaxBackups)
 	if err != nil {
-		message = fmt.Sprintf("%s: %v", message, err)
+		return nil, fmt.Errorf("open log file: %w", err)
 	}
-	l.log("ERROR", message, args...)
+	return file, nil
+}
+
+// replaceLevel names the fatal level, which slog would print as ERROR+4
+func replaceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelFatal {
+			attr.Value = slog.StringValue("FATAL")
+		}
+	}
+	return attr
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries the logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or fallback if there is none
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return fallback
+}
+
+// With returns a logger that adds the given fields to every record
+func (l *Logger) With(args ...interface{}) *Logger {
+	return &Logger{slog: l.slog.With(args...)}
 }
 
 // Debug logs debug messages
 func (l *Logger) Debug(message string, args ...interface{}) {
-	if l.level == "debug" {
-		l.log("DEBUG", message, args...)
-	}
+	l.slog.Debug(message, args...)
+}
+
+// Info logs information messages
+func (l *Logger) Info(message string, args ...interface{}) {
+	l.slog.Info(message, args...)
+}
+
+// Warn logs messages about unexpected but handled situations
+func (l *Logger) Warn(message string, args ...interface{}) {
+	l.slog.Warn(message, args...)
+}
+
+// Error logs error messages, with the error in the "error" field
+func (l *Logger) Error(message string, err error, args ...interface{}) {
+	l.slog.Error(message, withError(err, args)...)
 }
 
 // Fatal logs fatal messages and exits the program
 func (l *Logger) Fatal(message string, err error, args ...interface{}) {
-	if err != nil {
-		message = fmt.Sprintf("%s: %v", message, err)
-	}
-	l.log("FATAL", message, args...)
+	l.slog.Log(context.Background(), LevelFatal, message, withError(err, args)...)
 	os.Exit(1)
 }
 
-func (l *Logger) log(level, message string, args ...interface{}) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	if len(args) > 0 {
-		message = fmt.Sprintf(message, args...)
+func withError(err error, args []interface{}) []interface{} {
+	if err == nil {
+		return args
 	}
-	l.logger.Printf("[%s] %s: %s", level, timestamp, message)
+	return append([]interface{}{slog.Any("error", err)}, args...)
 }
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary
+// URLs do not create new series
+const unmatchedRoute = "unmatched"
+
+// NewRegistry creates a registry with the Go runtime, process and database
+// connection pool metrics
+func NewRegistry(db *sql.DB) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, "main"),
+	)
+	return registry
+}
+
+// Handler serves the metrics of a registry in the Prometheus text format
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// HTTP holds the metrics of served requests
+type HTTP struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTP creates and registers the HTTP request metrics
+func NewHTTP(registerer prometheus.Registerer) *HTTP {
+	labels := []string{"method", "route", "status"}
+
+	m := &HTTP{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests served, by route pattern and status.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to serve HTTP requests, by route pattern and status.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+	}
+	registerer.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// Observe records a served request
+func (m *HTTP) Observe(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.requests.With(labels).Inc()
+	m.duration.With(labels).Observe(duration.Seconds())
+}
+
+// Jobs holds the metrics of scheduled job runs
+type Jobs struct {
+	runs     *prometheus.CounterVec
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewJobs creates and registers the scheduled job metrics
+func NewJobs(registerer prometheus.Registerer) *Jobs {
+	m := &Jobs{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cron_job_runs_total",
+			Help: "Scheduled job runs, by job and what triggered them.",
+		}, []string{"job", "trigger"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cron_job_failures_total",
+			Help: "Scheduled job runs that failed, by job.",
+		}, []string{"job"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cron_job_duration_seconds",
+			Help:    "Time taken by scheduled job runs, by job.",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		}, []string{"job"}),
+	}
+	registerer.MustRegister(m.runs, m.failures, m.duration)
+	return m
+}
+
+// Observe records a finished job run
+func (m *Jobs) Observe(job, trigger string, duration time.Duration, err error) {
+	m.runs.WithLabelValues(job, trigger).Inc()
+	m.duration.WithLabelValues(job).Observe(duration.Seconds())
+	if err != nil {
+		m.failures.WithLabelValues(job).Inc()
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// taskCollector counts tasks from the database whenever metrics are scraped
+type taskCollector struct {
+	repo           *repository.TaskRepository
+	open           *prometheus.Desc
+	overdue        *prometheus.Desc
+	completedToday *prometheus.Desc
+	errors         *prometheus.Desc
+}
+
+// NewTaskCollector creates a collector of the open, overdue and completed
+// today task gauges
+func NewTaskCollector(repo *repository.TaskRepository) prometheus.Collector {
+	return &taskCollector{
+		repo:           repo,
+		open:           prometheus.NewDesc("tasks_open", "Tasks that are not completed.", nil, nil),
+		overdue:        prometheus.NewDesc("tasks_overdue", "Open tasks due before today (UTC).", nil, nil),
+		completedToday: prometheus.NewDesc("tasks_completed_today", "Tasks completed since midnight UTC.", nil, nil),
+		errors:         prometheus.NewDesc("tasks_scrape_error", "1 if counting the tasks failed.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.overdue
+	ch <- c.completedToday
+	ch <- c.errors
+}
+
+// Collect implements prometheus.Collector
+func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	// Due dates are stored as midnight UTC of the day the task is due
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	gauges := []struct {
+		desc   *prometheus.Desc
+		filter repository.TaskFilter
+	}{
+		{c.open, repository.TaskFilter{Open: true}},
+		{c.overdue, repository.TaskFilter{Open: true, DueBefore: &today}},
+		{c.completedToday, repository.TaskFilter{CompletedSince: &today}},
+	}
+
+	failed := 0.0
+	for _, gauge := range gauges {
+		count, err := c.repo.Count(context.Background(), gauge.filter)
+		if err != nil {
+			failed = 1
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(gauge.desc, prometheus.GaugeValue, float64(count))
+	}
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.GaugeValue, failed)
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Notification is a message addressed to a single user
+type Notification struct {
+	UserID  int    `json:"user_id"`
+	TaskID  *int   `json:"task_id,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// InboxNotifier delivers notifications to the user's in-app inbox
+type InboxNotifier struct {
+	repo   *repository.NotificationRepository
+	logger *logger.Logger
+}
+
+// NewInboxNotifier creates a new InboxNotifier
+func NewInboxNotifier(repo *repository.NotificationRepository, logger *logger.Logger) *InboxNotifier {
+	return &InboxNotifier{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Notify stores the notification in the user's inbox
+func (n *InboxNotifier) Notify(notification Notification) error {
+	_, err := n.repo.Create(&repository.Notification{
+		UserID:    notification.UserID,
+		TaskID:    notification.TaskID,
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	n.logger.Debug("Notified user", "user_id", notification.UserID, "subject", notification.Subject)
+	return nil
+}
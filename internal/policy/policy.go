@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"fmt"
+
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Workspace roles, from most to least privileged
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// Action is an operation subject to authorization
+type Action string
+
+// Actions checked by the services
+const (
+	ViewTask        Action = "view tasks"
+	CreateTask      Action = "create tasks"
+	EditTask        Action = "edit tasks"
+	AssignTask      Action = "assign tasks"
+	DeleteTask      Action = "delete tasks"
+	DeleteOwnTask   Action = "delete tasks they created"
+	CommentTask     Action = "comment on tasks"
+	TrackTime       Action = "track time on tasks"
+	CreateProject   Action = "create projects"
+	ManageBoards    Action = "create and configure boards"
+	ModerateComment Action = "edit or delete other users' comments"
+	ViewWorkspace   Action = "view the workspace"
+	ManageMembers   Action = "manage members"
+	ManagePrivilege Action = "grant or revoke the owner and admin roles"
+)
+
+// rolePermissions lists what each workspace role may do
+var rolePermissions = map[string][]Action{
+	RoleViewer: {ViewTask, ViewWorkspace},
+	RoleMember: {ViewTask, ViewWorkspace, CreateTask, EditTask, AssignTask, DeleteOwnTask, CommentTask, TrackTime},
+	RoleAdmin:  {ViewTask, ViewWorkspace, CreateTask, EditTask, AssignTask, DeleteOwnTask, CommentTask, TrackTime, DeleteTask, ManageMembers, ModerateComment, CreateProject, ManageBoards},
+	RoleOwner:  {ViewTask, ViewWorkspace, CreateTask, EditTask, AssignTask, DeleteOwnTask, CommentTask, TrackTime, DeleteTask, ManageMembers, ModerateComment, CreateProject, ManageBoards, ManagePrivilege},
+}
+
+// ValidRole reports whether role is a known workspace role
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Actor is the principal performing an action
+type Actor struct {
+	UserID int
+	System bool
+}
+
+// System is the actor used by background jobs and maintenance tools. It
+// bypasses role checks.
+var System = Actor{System: true}
+
+// User returns the actor for an authenticated user
+func User(id int) Actor {
+	return Actor{UserID: id}
+}
+
+// ID returns the user ID of the actor, or nil for the system actor
+func (a Actor) ID() *int {
+	if a.System {
+		return nil
+	}
+	id := a.UserID
+	return &id
+}
+
+// ForbiddenError reports an action the actor is not allowed to perform
+type ForbiddenError struct {
+	Action Action
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return "forbidden: " + e.Reason
+}
+
+// Policy decides whether an actor may perform an action
+type Policy struct {
+	workspaces *repository.WorkspaceRepository
+}
+
+// New creates a new Policy
+func New(workspaces *repository.WorkspaceRepository) *Policy {
+	return &Policy{
+		workspaces: workspaces,
+	}
+}
+
+// Role returns the actor's role in a workspace
+func (p *Policy) Role(actor Actor, workspaceID int) (string, error) {
+	if actor.System {
+		return RoleOwner, nil
+	}
+
+	member, err := p.workspaces.FindMember(workspaceID, actor.UserID)
+	if err == repository.ErrMemberNotFound {
+		return "", &ForbiddenError{Reason: fmt.Sprintf("you are not a member of workspace #%d", workspaceID)}
+	} else if err != nil {
+		return "", err
+	}
+
+	return member.Role, nil
+}
+
+// Workspace checks an action against the actor's role in a workspace
+func (p *Policy) Workspace(actor Actor, workspaceID int, action Action) error {
+	role, err := p.Role(actor, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if !allows(role, action) {
+		return &ForbiddenError{
+			Action: action,
+			Reason: fmt.Sprintf("your role %q in workspace #%d does not allow you to %s", role, workspaceID, action),
+		}
+	}
+
+	return nil
+}
+
+// Project checks an action on a project. Workspace projects follow the
+// actor's role; personal projects are only accessible to their owner.
+func (p *Policy) Project(actor Actor, project *repository.Project, action Action) error {
+	if actor.System {
+		return nil
+	}
+
+	if project.WorkspaceID != nil {
+		return p.Workspace(actor, *project.WorkspaceID, action)
+	}
+
+	if isUser(project.OwnerID, actor.UserID) {
+		return nil
+	}
+
+	return &ForbiddenError{
+		Action: action,
+		Reason: fmt.Sprintf("project #%d is a personal project of another user", project.ID),
+	}
+}
+
+// Task checks an action on a specific task. Workspace tasks follow the
+// actor's role; personal tasks belong to their creator, and their assignee
+// may view, edit, comment on and track time on them.
+func (p *Policy) Task(actor Actor, task *repository.Task, action Action) error {
+	if actor.System {
+		return nil
+	}
+
+	if task.WorkspaceID != nil {
+		if action == DeleteTask && isUser(task.CreatorID, actor.UserID) {
+			if err := p.Workspace(actor, *task.WorkspaceID, DeleteOwnTask); err == nil {
+				return nil
+			}
+		}
+		return p.Workspace(actor, *task.WorkspaceID, action)
+	}
+
+	if isUser(task.CreatorID, actor.UserID) {
+		return nil
+	}
+	if isUser(task.AssigneeID, actor.UserID) && (action == ViewTask || action == EditTask || action == CommentTask || action == TrackTime) {
+		return nil
+	}
+
+	return &ForbiddenError{
+		Action: action,
+		Reason: fmt.Sprintf("task #%d is a personal task of another user", task.ID),
+	}
+}
+
+func allows(role string, action Action) bool {
+	for _, permitted := range rolePermissions[role] {
+		if permitted == action {
+			return true
+		}
+	}
+	return false
+}
+
+func isUser(id *int, userID int) bool {
+	return id != nil && *id == userID
+}
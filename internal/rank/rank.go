@@ -0,0 +1,126 @@
+// Package rank generates lexicographic sort keys that allow inserting an
+// item between any two others without renumbering its neighbors. Generated
+// keys never end in the lowest digit '0': no key sorts between "b" and "b0",
+// so such a key would leave no room before it.
+package rank
+
+import "strings"
+
+const (
+	digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	base   = len(digits)
+)
+
+// Between returns a key sorting strictly after a and before b. An empty a
+// means the start of the list and an empty b its end. It reports false when
+// no such key exists, because a does not sort before b or because b is a
+// followed only by '0' digits.
+func Between(a, b string) (string, bool) {
+	if b != "" && a >= b {
+		return "", false
+	}
+
+	var key strings.Builder
+	for i := 0; ; i++ {
+		lower := digit(a, i, 0)
+		upper := base
+		if b != "" {
+			if i >= len(b) {
+				// The key so far equals b, so every longer key sorts after it
+				return "", false
+			}
+			upper = digit(b, i, 0)
+		}
+
+		if lower == upper {
+			key.WriteByte(digits[lower])
+			continue
+		}
+
+		if upper-lower > 1 {
+			key.WriteByte(digits[(lower+upper)/2])
+			return key.String(), true
+		}
+
+		// Adjacent digits: keep the lower one and find a key after the rest of a
+		key.WriteByte(digits[lower])
+		rest, _ := Between(suffix(a, i+1), "")
+		key.WriteString(rest)
+		return key.String(), true
+	}
+}
+
+// Spread returns n evenly spaced keys in ascending order, leaving room to
+// insert between any of them
+func Spread(n int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+
+	width, capacity := 1, base
+	for capacity < 4*(n+1) {
+		width++
+		capacity *= base
+	}
+
+	step := capacity / (n + 1)
+	keys := make([]string, n)
+	for i := range keys {
+		// Dropping trailing zeros keeps the keys in order
+		keys[i] = strings.TrimRight(format((i+1)*step, width), "0")
+	}
+	return keys
+}
+
+// SpreadBetween returns n ascending keys that sort strictly after a and
+// before b, with the same meaning of empty bounds as Between. It reports
+// false when there is no room between a and b.
+func SpreadBetween(a, b string, n int) ([]string, bool) {
+	if n <= 0 {
+		return []string{}, true
+	}
+
+	// Splitting at the middle key keeps the keys short
+	middle, ok := Between(a, b)
+	if !ok {
+		return nil, false
+	}
+	lower, _ := SpreadBetween(a, middle, n/2)
+	upper, _ := SpreadBetween(middle, b, n-n/2-1)
+
+	keys := append(lower, middle)
+	return append(keys, upper...), true
+}
+
+func digit(s string, i, fallback int) int {
+	if i >= len(s) {
+		return fallback
+	}
+	return strings.IndexByte(digits, s[i])
+}
+
+func suffix(s string, i int) string {
+	if i >= len(s) {
+		return ""
+	}
+	return s[i:]
+}
+
+func format(value, width int) string {
+	key := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		key[i] = digits[value%base]
+		value /= base
+	}
+	return string(key)
+}
+
+// After returns n ascending keys that all sort after last
+func After(last string, n int) []string {
+	prefix, _ := Between(last, "")
+	keys := Spread(n)
+	for i := range keys {
+		keys[i] = prefix + keys[i]
+	}
+	return keys
+}
@@ -0,0 +1,146 @@
+package rank
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		ok   bool
+	}{
+		{"", "", true},
+		{"a", "", true},
+		{"", "a", true},
+		{"a", "c", true},
+		{"a", "b", true},
+		{"az", "b", true},
+		{"b", "b1", true},
+		{"b", "b01", true},
+		{"b5", "b6", true},
+		{"zz", "", true},
+		{"", "01", true},
+		{"i", "i00001", true},
+		// No key sorts between a and a followed by zeros
+		{"b", "b0", false},
+		{"b", "b000", false},
+		{"", "0", false},
+		{"", "00", false},
+		// a must sort before b
+		{"b", "b", false},
+		{"c", "b", false},
+		{"b1", "b", false},
+	}
+
+	for _, tt := range tests {
+		key, ok := Between(tt.a, tt.b)
+		if ok != tt.ok {
+			t.Errorf("Between(%q, %q) = %q, %v; want ok %v", tt.a, tt.b, key, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if key <= tt.a || (tt.b != "" && key >= tt.b) {
+			t.Errorf("Between(%q, %q) = %q, not strictly between", tt.a, tt.b, key)
+		}
+		if strings.HasSuffix(key, "0") {
+			t.Errorf("Between(%q, %q) = %q, ends in '0'", tt.a, tt.b, key)
+		}
+	}
+}
+
+func TestBetweenRepeatedly(t *testing.T) {
+	// Inserting again and again right after (or before) the same key keeps
+	// finding room
+	for _, after := range []bool{true, false} {
+		lower, upper := "b", "c"
+		for i := 0; i < 200; i++ {
+			key, ok := Between(lower, upper)
+			if !ok {
+				t.Fatalf("no room between %q and %q after %d insertions", lower, upper, i)
+			}
+			if after {
+				upper = key
+			} else {
+				lower = key
+			}
+		}
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 7, 35, 36, 100, 1000} {
+		keys := Spread(n)
+		if len(keys) != n {
+			t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+		}
+		checkAscending(t, keys, "", "")
+	}
+}
+
+func TestSpreadBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		n    int
+		ok   bool
+	}{
+		{"", "", 10, true},
+		{"b", "c", 1, true},
+		{"b", "c", 50, true},
+		{"b", "b1", 20, true},
+		{"b", "b", 0, true},
+		{"b", "b", 3, false},
+		{"b", "b0", 3, false},
+	}
+
+	for _, tt := range tests {
+		keys, ok := SpreadBetween(tt.a, tt.b, tt.n)
+		if ok != tt.ok {
+			t.Errorf("SpreadBetween(%q, %q, %d) ok = %v, want %v", tt.a, tt.b, tt.n, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if len(keys) != tt.n {
+			t.Errorf("SpreadBetween(%q, %q, %d) returned %d keys", tt.a, tt.b, tt.n, len(keys))
+		}
+		checkAscending(t, keys, tt.a, tt.b)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	for _, last := range []string{"", "b", "z", "zzz", "i5"} {
+		keys := After(last, 25)
+		if len(keys) != 25 {
+			t.Fatalf("After(%q, 25) returned %d keys", last, len(keys))
+		}
+		checkAscending(t, keys, last, "")
+	}
+}
+
+// checkAscending verifies that keys are strictly ascending, within the
+// bounds, never end in '0' and leave room between neighbors
+func checkAscending(t *testing.T, keys []string, lower, upper string) {
+	t.Helper()
+
+	if !sort.StringsAreSorted(keys) {
+		t.Fatalf("keys are not sorted: %q", keys)
+	}
+	previous := lower
+	for _, key := range keys {
+		if key <= previous || (upper != "" && key >= upper) {
+			t.Fatalf("key %q is out of order or bounds (%q, %q) in %q", key, lower, upper, keys)
+		}
+		if strings.HasSuffix(key, "0") {
+			t.Fatalf("key %q ends in '0'", key)
+		}
+		if _, ok := Between(previous, key); !ok && previous != "" {
+			t.Fatalf("no room between %q and %q", previous, key)
+		}
+		previous = key
+	}
+}
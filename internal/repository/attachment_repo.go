@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Attachment is a file uploaded to a task. The content lives in a blob
+// store under StorageKey.
+type Attachment struct {
+	ID          int       `json:"id"`
+	TaskID      int       `json:"task_id"`
+	UploaderID  *int      `json:"uploader_id,omitempty"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentRepository handles DB operations for attachments
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+// NewAttachmentRepository creates a new AttachmentRepository
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{
+		db: db,
+	}
+}
+
+// Initialize creates attachments table if it doesn't exist
+func (r *AttachmentRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		uploader_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL UNIQUE,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_attachments_task ON attachments(task_id);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const attachmentColumns = `id, task_id, uploader_id, filename, content_type, size, storage_key, created_at`
+
+func scanAttachment(s rowScanner) (*Attachment, error) {
+	var a Attachment
+	err := s.Scan(&a.ID, &a.TaskID, &a.UploaderID, &a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// FindByTask returns the attachments of a task, oldest first
+func (r *AttachmentRepository) FindByTask(taskID int) ([]Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE task_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// FindByID returns an attachment by ID
+func (r *AttachmentRepository) FindByID(id int) (*Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = ?`
+
+	a, err := scanAttachment(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+// Create adds a new attachment
+func (r *AttachmentRepository) Create(attachment *Attachment) (*Attachment, error) {
+	query := `
+	INSERT INTO attachments (task_id, uploader_id, filename, content_type, size, storage_key, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		attachment.TaskID,
+		attachment.UploaderID,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		attachment.CreatedAt,
+	).Scan(&attachment.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Delete removes an attachment
+func (r *AttachmentRepository) Delete(id int) error {
+	query := `DELETE FROM attachments WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Board is a Kanban view over the tasks of a project, a workspace or, when
+// neither is set, the personal tasks of its owner
+type Board struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	WorkspaceID *int          `json:"workspace_id,omitempty"`
+	ProjectID   *int          `json:"project_id,omitempty"`
+	OwnerID     *int          `json:"owner_id,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Columns     []BoardColumn `json:"columns"`
+}
+
+// BoardColumn shows the tasks with a given status. A WIPLimit caps the
+// number of tasks that may be moved into the column.
+type BoardColumn struct {
+	ID       int    `json:"id"`
+	BoardID  int    `json:"board_id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Position int    `json:"position"`
+	WIPLimit *int   `json:"wip_limit,omitempty"`
+}
+
+// BoardRepository handles DB operations for boards and their columns
+type BoardRepository struct {
+	db *sql.DB
+}
+
+// NewBoardRepository creates a new BoardRepository
+func NewBoardRepository(db *sql.DB) *BoardRepository {
+	return &BoardRepository{
+		db: db,
+	}
+}
+
+// Initialize creates boards and board_columns tables if they don't exist
+func (r *BoardRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS boards (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+		project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+		owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS board_columns (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		status TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		wip_limit INTEGER,
+		UNIQUE (board_id, status)
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindVisibleTo returns the boards of the user's workspaces and their personal boards
+func (r *BoardRepository) FindVisibleTo(userID int) ([]Board, error) {
+	query := `
+	SELECT id, name, workspace_id, project_id, owner_id, created_at FROM boards
+	WHERE workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?)
+		OR (workspace_id IS NULL AND owner_id = ?)
+	ORDER BY name`
+
+	rows, err := r.db.Query(query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	boards := []Board{}
+	for rows.Next() {
+		var b Board
+		if err := rows.Scan(&b.ID, &b.Name, &b.WorkspaceID, &b.ProjectID, &b.OwnerID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		boards = append(boards, b)
+	}
+
+	return boards, rows.Err()
+}
+
+// FindByID returns a board with its columns
+func (r *BoardRepository) FindByID(id int) (*Board, error) {
+	query := `SELECT id, name, workspace_id, project_id, owner_id, created_at FROM boards WHERE id = ?`
+
+	var b Board
+	err := r.db.QueryRow(query, id).Scan(&b.ID, &b.Name, &b.WorkspaceID, &b.ProjectID, &b.OwnerID, &b.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrBoardNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	b.Columns, err = r.findColumns(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// FindColumn returns a board column by ID
+func (r *BoardRepository) FindColumn(id int) (*BoardColumn, error) {
+	query := `SELECT id, board_id, name, status, position, wip_limit FROM board_columns WHERE id = ?`
+
+	var c BoardColumn
+	err := r.db.QueryRow(query, id).Scan(&c.ID, &c.BoardID, &c.Name, &c.Status, &c.Position, &c.WIPLimit)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrBoardColumnNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *BoardRepository) findColumns(boardID int) ([]BoardColumn, error) {
+	query := `
+	SELECT id, board_id, name, status, position, wip_limit
+	FROM board_columns WHERE board_id = ? ORDER BY position, id`
+
+	rows, err := r.db.Query(query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []BoardColumn{}
+	for rows.Next() {
+		var c BoardColumn
+		if err := rows.Scan(&c.ID, &c.BoardID, &c.Name, &c.Status, &c.Position, &c.WIPLimit); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+
+	return columns, rows.Err()
+}
+
+// Create adds a new board with its columns
+func (r *BoardRepository) Create(board *Board) (*Board, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO boards (name, workspace_id, project_id, owner_id, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`,
+		board.Name,
+		board.WorkspaceID,
+		board.ProjectID,
+		board.OwnerID,
+		board.CreatedAt,
+	).Scan(&board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveColumns(tx, board.ID, board.Columns); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(board.ID)
+}
+
+// SaveColumns replaces the columns of a board. Columns with an ID are
+// updated in place, columns without one are added and columns missing from
+// the list are removed.
+func (r *BoardRepository) SaveColumns(boardID int, columns []BoardColumn) (*Board, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := saveColumns(tx, boardID, columns); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(boardID)
+}
+
+func saveColumns(tx *sql.Tx, boardID int, columns []BoardColumn) error {
+	// Clear statuses first so that columns may swap them without
+	// violating the unique constraint
+	if _, err := tx.Exec(`UPDATE board_columns SET status = '#' || id WHERE board_id = ?`, boardID); err != nil {
+		return err
+	}
+
+	keep := []interface{}{boardID}
+	placeholders := ""
+	for i, c := range columns {
+		if c.ID == 0 {
+			continue
+		}
+		keep = append(keep, c.ID)
+		if placeholders != "" {
+			placeholders += ", "
+		}
+		placeholders += "?"
+		columns[i].BoardID = boardID
+	}
+
+	query := `DELETE FROM board_columns WHERE board_id = ?`
+	if placeholders != "" {
+		query += ` AND id NOT IN (` + placeholders + `)`
+	}
+	if _, err := tx.Exec(query, keep...); err != nil {
+		return err
+	}
+
+	for i, c := range columns {
+		if c.ID != 0 {
+			result, err := tx.Exec(
+				`UPDATE board_columns SET name = ?, status = ?, position = ?, wip_limit = ? WHERE id = ? AND board_id = ?`,
+				c.Name, c.Status, i+1, c.WIPLimit, c.ID, boardID,
+			)
+			if err != nil {
+				return err
+			}
+			if n, err := result.RowsAffected(); err != nil {
+				return err
+			} else if n == 0 {
+				return ErrBoardColumnNotFound
+			}
+			continue
+		}
+
+		_, err := tx.Exec(
+			`INSERT INTO board_columns (board_id, name, status, position, wip_limit) VALUES (?, ?, ?, ?, ?)`,
+			boardID, c.Name, c.Status, i+1, c.WIPLimit,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
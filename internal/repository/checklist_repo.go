@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ChecklistItem is an ordered, checkable line inside a task
+type ChecklistItem struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	Position  int        `json:"position"`
+	Text      string     `json:"text"`
+	Checked   bool       `json:"checked"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ChecklistRepository handles DB operations for checklist items
+type ChecklistRepository struct {
+	db *sql.DB
+}
+
+// NewChecklistRepository creates a new ChecklistRepository
+func NewChecklistRepository(db *sql.DB) *ChecklistRepository {
+	return &ChecklistRepository{
+		db: db,
+	}
+}
+
+// Initialize creates checklist_items table if it doesn't exist
+func (r *ChecklistRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS checklist_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		checked BOOLEAN NOT NULL DEFAULT FALSE,
+		checked_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_checklist_items_task ON checklist_items(task_id, position);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindByTask returns the checklist of a task in order
+func (r *ChecklistRepository) FindByTask(taskID int) ([]ChecklistItem, error) {
+	query := `
+	SELECT id, task_id, position, text, checked, checked_at, created_at
+	FROM checklist_items WHERE task_id = ? ORDER BY position, id`
+
+	rows, err := r.db.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ChecklistItem{}
+	for rows.Next() {
+		var i ChecklistItem
+		if err := rows.Scan(&i.ID, &i.TaskID, &i.Position, &i.Text, &i.Checked, &i.CheckedAt, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	return items, rows.Err()
+}
+
+// FindByID returns a checklist item of a task
+func (r *ChecklistRepository) FindByID(taskID, id int) (*ChecklistItem, error) {
+	query := `
+	SELECT id, task_id, position, text, checked, checked_at, created_at
+	FROM checklist_items WHERE task_id = ? AND id = ?`
+
+	var i ChecklistItem
+	err := r.db.QueryRow(query, taskID, id).Scan(&i.ID, &i.TaskID, &i.Position, &i.Text, &i.Checked, &i.CheckedAt, &i.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrChecklistItemNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+// Create appends an item to the end of a task's checklist
+func (r *ChecklistRepository) Create(item *ChecklistItem) (*ChecklistItem, error) {
+	query := `
+	INSERT INTO checklist_items (task_id, position, text, checked, checked_at, created_at)
+	VALUES (?, (SELECT COALESCE(MAX(position), 0) + 1 FROM checklist_items WHERE task_id = ?), ?, ?, ?, ?)
+	RETURNING id, position`
+
+	err := r.db.QueryRow(
+		query,
+		item.TaskID,
+		item.TaskID,
+		item.Text,
+		item.Checked,
+		item.CheckedAt,
+		item.CreatedAt,
+	).Scan(&item.ID, &item.Position)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Update modifies the text and checked state of an item
+func (r *ChecklistRepository) Update(item *ChecklistItem) (*ChecklistItem, error) {
+	query := `UPDATE checklist_items SET text = ?, checked = ?, checked_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, item.Text, item.Checked, item.CheckedAt, item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Reorder renumbers a task's checklist to follow the given item IDs
+func (r *ChecklistRepository) Reorder(taskID int, ids []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for position, id := range ids {
+		_, err := tx.Exec(`UPDATE checklist_items SET position = ? WHERE task_id = ? AND id = ?`, position+1, taskID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a checklist item
+func (r *ChecklistRepository) Delete(id int) error {
+	query := `DELETE FROM checklist_items WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
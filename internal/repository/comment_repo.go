@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Comment is a Markdown message posted on a task
+type Comment struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	AuthorID  *int      `json:"author_id,omitempty"`
+	Body      string    `json:"body"`
+	BodyHTML  string    `json:"body_html"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CommentRepository handles DB operations for comments
+type CommentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository creates a new CommentRepository
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{
+		db: db,
+	}
+}
+
+// Initialize creates comments table if it doesn't exist
+func (r *CommentRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_comments_task ON comments(task_id);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindByTask returns the comments of a task, oldest first
+func (r *CommentRepository) FindByTask(taskID int) ([]Comment, error) {
+	query := `
+	SELECT id, task_id, author_id, body, created_at, updated_at
+	FROM comments WHERE task_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.TaskID, &c.AuthorID, &c.Body, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// FindByID returns a comment by ID
+func (r *CommentRepository) FindByID(id int) (*Comment, error) {
+	query := `SELECT id, task_id, author_id, body, created_at, updated_at FROM comments WHERE id = ?`
+
+	var c Comment
+	err := r.db.QueryRow(query, id).Scan(&c.ID, &c.TaskID, &c.AuthorID, &c.Body, &c.CreatedAt, &c.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrCommentNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Create adds a new comment
+func (r *CommentRepository) Create(comment *Comment) (*Comment, error) {
+	query := `
+	INSERT INTO comments (task_id, author_id, body, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		comment.TaskID,
+		comment.AuthorID,
+		comment.Body,
+		comment.CreatedAt,
+		comment.UpdatedAt,
+	).Scan(&comment.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// Update modifies the body of a comment
+func (r *CommentRepository) Update(comment *Comment) (*Comment, error) {
+	query := `UPDATE comments SET body = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, comment.Body, comment.UpdatedAt, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// Delete removes a comment
+func (r *CommentRepository) Delete(id int) error {
+	query := `DELETE FROM comments WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"strings"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
@@ -17,6 +19,15 @@ func NewDatabase(url string) (*sql.DB, error) {
 	
 	driver := parts[0]
 	dataSource := parts[1]
+
+	// SQLite only enforces foreign keys (and their ON DELETE actions) when asked to
+	if driver == "sqlite3" && !strings.Contains(dataSource, "_foreign_keys") {
+		separator := "?"
+		if strings.Contains(dataSource, "?") {
+			separator = "&"
+		}
+		dataSource += separator + "_foreign_keys=on"
+	}
 	
 	// Open database connection
 	db, err := sql.Open(driver, dataSource)
@@ -29,18 +40,100 @@ func NewDatabase(url string) (*sql.DB, error) {
 		return nil, err
 	}
 	
-	// Initialize task repository
-	taskRepo := NewTaskRepository(db)
-	if err := taskRepo.Initialize(); err != nil {
+	// Initialize repositories
+	if err := NewRepositories(db).Initialize(); err != nil {
 		return nil, err
 	}
 	
 	return db, nil
 }
 
+// StringList is a list of strings stored as a comma separated column
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("unsupported type %T for string list", src)
+	}
+
+	*l = StringList{}
+	for _, item := range strings.Split(s, ",") {
+		if item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column to an existing table. CREATE TABLE IF NOT
+// EXISTS leaves databases created by older versions untouched, so new columns
+// have to be added explicitly.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 // Error definitions
 var (
-	ErrInvalidDatabaseURL = New("invalid database URL")
+	ErrInvalidDatabaseURL     = New("invalid database URL")
+	ErrTaskNotFound           = New("task not found")
+	ErrUserNotFound           = New("user not found")
+	ErrWorkspaceNotFound      = New("workspace not found")
+	ErrMemberNotFound         = New("workspace member not found")
+	ErrTokenNotFound          = New("token not found")
+	ErrCommentNotFound        = New("comment not found")
+	ErrAttachmentNotFound     = New("attachment not found")
+	ErrProjectNotFound        = New("project not found")
+	ErrTimeEntryNotFound      = New("time entry not found")
+	ErrChecklistItemNotFound  = New("checklist item not found")
+	ErrBoardNotFound          = New("board not found")
+	ErrBoardColumnNotFound    = New("board column not found")
+	ErrTemplateNotFound       = New("template not found")
+	ErrReminderNotFound       = New("reminder not found")
+	ErrDigestSettingsNotFound = New("digest settings not found")
+	ErrJobNotFound            = New("job not found")
+	ErrLeaseLost              = New("lease is held by another owner")
+	ErrQueueJobNotFound       = New("queued job not found")
+	ErrTimerRunning           = New("a timer is already running")
 )
 
 // New creates a new error
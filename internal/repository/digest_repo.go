@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DigestSettings holds when a user receives summary emails
+type DigestSettings struct {
+	UserID int `json:"user_id"`
+	// Frequency is "daily", "weekly" or "off"
+	Frequency string `json:"frequency"`
+	// Hour (0-23) and, for weekly digests, Weekday are in the user's Timezone
+	Hour       int        `json:"hour"`
+	Weekday    string     `json:"weekday"`
+	Timezone   string     `json:"timezone"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// DigestRepository handles DB operations for digest settings
+type DigestRepository struct {
+	db *sql.DB
+}
+
+// NewDigestRepository creates a new DigestRepository
+func NewDigestRepository(db *sql.DB) *DigestRepository {
+	return &DigestRepository{
+		db: db,
+	}
+}
+
+// Initialize creates digest_settings table if it doesn't exist
+func (r *DigestRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS digest_settings (
+		user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		frequency TEXT NOT NULL,
+		hour INTEGER NOT NULL,
+		weekday TEXT NOT NULL,
+		timezone TEXT NOT NULL,
+		last_sent_at DATETIME,
+		updated_at DATETIME NOT NULL
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindByUser returns the digest settings a user has saved
+func (r *DigestRepository) FindByUser(userID int) (*DigestSettings, error) {
+	query := `
+	SELECT user_id, frequency, hour, weekday, timezone, last_sent_at, updated_at
+	FROM digest_settings WHERE user_id = ?`
+
+	var d DigestSettings
+	err := r.db.QueryRow(query, userID).Scan(&d.UserID, &d.Frequency, &d.Hour, &d.Weekday, &d.Timezone, &d.LastSentAt, &d.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrDigestSettingsNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// Save creates or replaces the digest settings of a user
+func (r *DigestRepository) Save(settings *DigestSettings) (*DigestSettings, error) {
+	query := `
+	INSERT INTO digest_settings (user_id, frequency, hour, weekday, timezone, last_sent_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (user_id) DO UPDATE SET
+		frequency = excluded.frequency,
+		hour = excluded.hour,
+		weekday = excluded.weekday,
+		timezone = excluded.timezone,
+		last_sent_at = excluded.last_sent_at,
+		updated_at = excluded.updated_at`
+
+	_, err := r.db.Exec(
+		query,
+		settings.UserID,
+		settings.Frequency,
+		settings.Hour,
+		settings.Weekday,
+		settings.Timezone,
+		settings.LastSentAt,
+		settings.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// TaskHistory actions
+const (
+	HistoryAssigned        = "assigned"
+	HistoryStatusChanged   = "status_changed"
+	HistoryMoved           = "moved"
+	HistoryDuplicated      = "duplicated"
+	HistorySnoozed         = "snoozed"
+	HistoryWoken           = "woken"
+	HistoryEscalated       = "escalated"
+	HistoryPriorityChanged = "priority_changed"
+)
+
+// TaskHistoryEntry records a change made to a task
+type TaskHistoryEntry struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	ActorID   *int      `json:"actor_id,omitempty"`
+	Action    string    `json:"action"`
+	OldValue  string    `json:"old_value,omitempty"`
+	NewValue  string    `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HistoryRepository handles DB operations for task history
+type HistoryRepository struct {
+	db *sql.DB
+}
+
+// NewHistoryRepository creates a new HistoryRepository
+func NewHistoryRepository(db *sql.DB) *HistoryRepository {
+	return &HistoryRepository{
+		db: db,
+	}
+}
+
+// Initialize creates task_history table if it doesn't exist
+func (r *HistoryRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS task_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		action TEXT NOT NULL,
+		old_value TEXT NOT NULL DEFAULT '',
+		new_value TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_history_task ON task_history(task_id);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindByTask returns the history of a task, oldest first
+func (r *HistoryRepository) FindByTask(taskID int) ([]TaskHistoryEntry, error) {
+	query := `
+	SELECT id, task_id, actor_id, action, old_value, new_value, created_at
+	FROM task_history WHERE task_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []TaskHistoryEntry{}
+	for rows.Next() {
+		var e TaskHistoryEntry
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.ActorID, &e.Action, &e.OldValue, &e.NewValue, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+const insertHistoryQuery = `
+	INSERT INTO task_history (task_id, actor_id, action, old_value, new_value, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+// Create records a history entry
+func (r *HistoryRepository) Create(entry *TaskHistoryEntry) (*TaskHistoryEntry, error) {
+	err := r.db.QueryRow(
+		insertHistoryQuery,
+		entry.TaskID,
+		entry.ActorID,
+		entry.Action,
+		entry.OldValue,
+		entry.NewValue,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// insertHistory records a history entry as part of a larger write, such as
+// a transaction of the TaskRepository
+func insertHistory(ctx context.Context, q queryRower, entry *TaskHistoryEntry) error {
+	return q.QueryRowContext(
+		ctx,
+		insertHistoryQuery,
+		entry.TaskID,
+		entry.ActorID,
+		entry.Action,
+		entry.OldValue,
+		entry.NewValue,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Job run states
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// What started a job run
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerCatchUp  = "catch_up"
+)
+
+// ScheduledJob is a recurring job as last registered by a scheduler
+type ScheduledJob struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	// RequestedAt is set while a manual run waits for a scheduler to pick it up
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// JobRun records a single execution of a scheduled job
+type JobRun struct {
+	ID         int        `json:"id"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	Instance   string     `json:"instance"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Items      int        `json:"items"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// DurationMS is computed when reading and ignored on writes
+	DurationMS *int64 `json:"duration_ms,omitempty"`
+}
+
+// JobRepository handles DB operations for scheduled jobs and their runs
+type JobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new JobRepository
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// Initialize creates scheduled_jobs and job_runs tables if they don't exist
+func (r *JobRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS scheduled_jobs (
+		name TEXT PRIMARY KEY,
+		schedule TEXT NOT NULL,
+		next_run_at DATETIME,
+		requested_at DATETIME,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS job_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job TEXT NOT NULL,
+		trigger TEXT NOT NULL,
+		instance TEXT NOT NULL,
+		status TEXT NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		items INTEGER NOT NULL DEFAULT 0,
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job, started_at);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindScheduled returns all registered jobs by name
+func (r *JobRepository) FindScheduled() ([]ScheduledJob, error) {
+	rows, err := r.db.Query(`SELECT name, schedule, next_run_at, requested_at, updated_at FROM scheduled_jobs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []ScheduledJob{}
+	for rows.Next() {
+		var j ScheduledJob
+		if err := rows.Scan(&j.Name, &j.Schedule, &j.NextRunAt, &j.RequestedAt, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, rows.Err()
+}
+
+// FindScheduledByName returns a registered job
+func (r *JobRepository) FindScheduledByName(name string) (*ScheduledJob, error) {
+	query := `SELECT name, schedule, next_run_at, requested_at, updated_at FROM scheduled_jobs WHERE name = ?`
+
+	var j ScheduledJob
+	err := r.db.QueryRow(query, name).Scan(&j.Name, &j.Schedule, &j.NextRunAt, &j.RequestedAt, &j.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+// SaveScheduled registers a job or updates its schedule and next run,
+// keeping any pending run request
+func (r *JobRepository) SaveScheduled(job *ScheduledJob) error {
+	query := `
+	INSERT INTO scheduled_jobs (name, schedule, next_run_at, updated_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET
+		schedule = excluded.schedule,
+		next_run_at = excluded.next_run_at,
+		updated_at = excluded.updated_at`
+
+	_, err := r.db.Exec(query, job.Name, job.Schedule, job.NextRunAt, job.UpdatedAt)
+	return err
+}
+
+// RequestRun asks the schedulers to run a job as soon as possible
+func (r *JobRepository) RequestRun(name string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE scheduled_jobs SET requested_at = ? WHERE name = ?`, at, name)
+	return err
+}
+
+// FindRequested returns the names of the jobs with a pending run request
+func (r *JobRepository) FindRequested() ([]string, error) {
+	rows, err := r.db.Query(`SELECT name FROM scheduled_jobs WHERE requested_at IS NOT NULL ORDER BY requested_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// ClaimRequest clears the run request of a job. It reports false when there
+// was none, e.g. because another scheduler claimed it first.
+func (r *JobRepository) ClaimRequest(name string) (bool, error) {
+	result, err := r.db.Exec(`UPDATE scheduled_jobs SET requested_at = NULL WHERE name = ? AND requested_at IS NOT NULL`, name)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := result.RowsAffected()
+	return claimed == 1, err
+}
+
+const jobRunColumns = `id, job, trigger, instance, status, error, items, started_at, finished_at`
+
+func scanJobRun(s rowScanner) (*JobRun, error) {
+	var run JobRun
+	err := s.Scan(&run.ID, &run.Job, &run.Trigger, &run.Instance, &run.Status, &run.Error, &run.Items, &run.StartedAt, &run.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if run.FinishedAt != nil {
+		duration := run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+		run.DurationMS = &duration
+	}
+	return &run, nil
+}
+
+// FindRuns returns the most recent runs of a job, newest first
+func (r *JobRepository) FindRuns(job string, limit int) ([]JobRun, error) {
+	query := `SELECT ` + jobRunColumns + ` FROM job_runs WHERE job = ? ORDER BY started_at DESC, id DESC LIMIT ?`
+
+	rows, err := r.db.Query(query, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []JobRun{}
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, rows.Err()
+}
+
+const insertRunQuery = `
+	INSERT INTO job_runs (job, trigger, instance, status, error, items, started_at, finished_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+// CreateRun records the start of a job run
+func (r *JobRepository) CreateRun(run *JobRun) (*JobRun, error) {
+	err := r.db.QueryRow(
+		insertRunQuery,
+		run.Job,
+		run.Trigger,
+		run.Instance,
+		run.Status,
+		run.Error,
+		run.Items,
+		run.StartedAt,
+		run.FinishedAt,
+	).Scan(&run.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// CreateRunHolding records the start of a job run only if the lease is
+// still held, checked in the same transaction. A former holder that has not
+// noticed losing the lease gets ErrLeaseLost and must not run the job.
+func (r *JobRepository) CreateRunHolding(run *JobRun, lease Lease) (*JobRun, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := holdsLease(tx, lease, run.StartedAt); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		insertRunQuery,
+		run.Job,
+		run.Trigger,
+		run.Instance,
+		run.Status,
+		run.Error,
+		run.Items,
+		run.StartedAt,
+		run.FinishedAt,
+	).Scan(&run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// FinishRun records the outcome of a job run
+func (r *JobRepository) FinishRun(run *JobRun) error {
+	query := `UPDATE job_runs SET status = ?, error = ?, items = ?, finished_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, run.Status, run.Error, run.Items, run.FinishedAt, run.ID)
+	return err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Lease identifies a lease held by an owner
+type Lease struct {
+	Name  string
+	Owner string
+}
+
+// LeaseRepository handles DB operations for leases, named locks that expire
+// unless their owner renews them
+type LeaseRepository struct {
+	db *sql.DB
+}
+
+// NewLeaseRepository creates a new LeaseRepository
+func NewLeaseRepository(db *sql.DB) *LeaseRepository {
+	return &LeaseRepository{
+		db: db,
+	}
+}
+
+// Initialize creates leases table if it doesn't exist
+func (r *LeaseRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS leases (
+		name TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		expires_at DATETIME NOT NULL
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// Acquire takes or renews the named lease for owner until now+ttl. It
+// reports false when another owner holds a lease that has not expired.
+func (r *LeaseRepository) Acquire(name, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	query := `
+	INSERT INTO leases (name, owner, expires_at) VALUES (?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+	WHERE leases.owner = excluded.owner OR leases.expires_at < ?`
+
+	now = now.UTC()
+	result, err := r.db.Exec(query, name, owner, now.Add(ttl), now)
+	if err != nil {
+		return false, err
+	}
+
+	acquired, err := result.RowsAffected()
+	return acquired == 1, err
+}
+
+// holdsLease checks, as part of a transaction, that owner still holds an
+// unexpired lease. It fails with ErrLeaseLost otherwise.
+func holdsLease(tx *sql.Tx, lease Lease, now time.Time) error {
+	var held int
+	err := tx.QueryRow(
+		`SELECT COUNT(*) FROM leases WHERE name = ? AND owner = ? AND expires_at > ?`,
+		lease.Name,
+		lease.Owner,
+		now.UTC(),
+	).Scan(&held)
+	if err != nil {
+		return err
+	}
+	if held == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release gives up the named lease if owner holds it
+func (r *LeaseRepository) Release(name, owner string) error {
+	_, err := r.db.Exec(`DELETE FROM leases WHERE name = ? AND owner = ?`, name, owner)
+	return err
+}
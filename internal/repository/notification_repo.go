@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Notification is a message delivered to a user's inbox
+type Notification struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	TaskID    *int       `json:"task_id,omitempty"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationRepository handles DB operations for notifications
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// Initialize creates notifications table if it doesn't exist
+func (r *NotificationRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		task_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		read_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindByUser returns a user's notifications, newest first
+func (r *NotificationRepository) FindByUser(userID int) ([]Notification, error) {
+	query := `
+	SELECT id, user_id, task_id, subject, body, read_at, created_at
+	FROM notifications WHERE user_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.TaskID, &n.Subject, &n.Body, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// Create stores a notification
+func (r *NotificationRepository) Create(n *Notification) (*Notification, error) {
+	query := `
+	INSERT INTO notifications (user_id, task_id, subject, body, created_at)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(query, n.UserID, n.TaskID, n.Subject, n.Body, n.CreatedAt).Scan(&n.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// MarkRead marks a user's notification as read
+func (r *NotificationRepository) MarkRead(id, userID int, at time.Time) error {
+	query := `UPDATE notifications SET read_at = ? WHERE id = ? AND user_id = ? AND read_at IS NULL`
+
+	_, err := r.db.Exec(query, at, id, userID)
+	return err
+}
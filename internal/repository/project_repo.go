@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Project groups related tasks. Projects either belong to a workspace or
+// are personal projects of their owner.
+type Project struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	WorkspaceID *int      `json:"workspace_id,omitempty"`
+	OwnerID     *int      `json:"owner_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProjectRepository handles DB operations for projects
+type ProjectRepository struct {
+	db *sql.DB
+}
+
+// NewProjectRepository creates a new ProjectRepository
+func NewProjectRepository(db *sql.DB) *ProjectRepository {
+	return &ProjectRepository{
+		db: db,
+	}
+}
+
+// Initialize creates projects table if it doesn't exist
+func (r *ProjectRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS projects (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+		owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at DATETIME NOT NULL
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindVisibleTo returns the projects in the user's workspaces and their personal projects
+func (r *ProjectRepository) FindVisibleTo(userID int) ([]Project, error) {
+	query := `
+	SELECT id, name, workspace_id, owner_id, created_at FROM projects
+	WHERE workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?)
+		OR (workspace_id IS NULL AND owner_id = ?)
+	ORDER BY name`
+
+	rows, err := r.db.Query(query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		var p Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.WorkspaceID, &p.OwnerID, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+// FindByID returns a project by ID
+func (r *ProjectRepository) FindByID(id int) (*Project, error) {
+	query := `SELECT id, name, workspace_id, owner_id, created_at FROM projects WHERE id = ?`
+
+	var p Project
+	err := r.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.WorkspaceID, &p.OwnerID, &p.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrProjectNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Create adds a new project
+func (r *ProjectRepository) Create(project *Project) (*Project, error) {
+	query := `
+	INSERT INTO projects (name, workspace_id, owner_id, created_at)
+	VALUES (?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(query, project.Name, project.WorkspaceID, project.OwnerID, project.CreatedAt).Scan(&project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
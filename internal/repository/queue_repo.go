@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Queue job states. A worker claims a queued job (running) for a visibility
+// timeout; if it neither completes nor fails the job in time, another worker
+// picks it up again. Jobs that used up their attempts are dead until retried.
+const (
+	QueueJobQueued  = "queued"
+	QueueJobRunning = "running"
+	QueueJobDone    = "done"
+	QueueJobDead    = "dead"
+)
+
+// QueueJob is a unit of background work
+type QueueJob struct {
+	ID          int             `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	// VisibleAt is when the job can be claimed next: its first or next
+	// attempt for queued jobs, the end of the visibility timeout for running ones
+	VisibleAt  time.Time  `json:"visible_at"`
+	LockedBy   string     `json:"locked_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// QueueRepository handles DB operations for the background job queue
+type QueueRepository struct {
+	db *sql.DB
+}
+
+// NewQueueRepository creates a new QueueRepository
+func NewQueueRepository(db *sql.DB) *QueueRepository {
+	return &QueueRepository{
+		db: db,
+	}
+}
+
+// Initialize creates jobs table if it doesn't exist
+func (r *QueueRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'queued',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL,
+		last_error TEXT NOT NULL DEFAULT '',
+		visible_at DATETIME NOT NULL,
+		locked_by TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		finished_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_jobs_status_visible_at ON jobs(status, visible_at);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const queueJobColumns = `id, kind, payload, status, attempts, max_attempts, last_error, visible_at, locked_by, created_at, updated_at, finished_at`
+
+func scanQueueJob(s rowScanner) (*QueueJob, error) {
+	var job QueueJob
+	var payload string
+	err := s.Scan(
+		&job.ID,
+		&job.Kind,
+		&payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.VisibleAt,
+		&job.LockedBy,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = json.RawMessage(payload)
+	return &job, nil
+}
+
+// FindAll returns the most recent jobs, optionally only those in a status
+func (r *QueueRepository) FindAll(status string, limit int) ([]QueueJob, error) {
+	query := `SELECT ` + queueJobColumns + ` FROM jobs WHERE (? = '' OR status = ?) ORDER BY id DESC LIMIT ?`
+
+	rows, err := r.db.Query(query, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []QueueJob{}
+	for rows.Next() {
+		job, err := scanQueueJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// FindByID returns a job
+func (r *QueueRepository) FindByID(id int) (*QueueJob, error) {
+	query := `SELECT ` + queueJobColumns + ` FROM jobs WHERE id = ?`
+
+	job, err := scanQueueJob(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrQueueJobNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Create adds a job to the queue
+func (r *QueueRepository) Create(job *QueueJob) (*QueueJob, error) {
+	query := `
+	INSERT INTO jobs (kind, payload, status, attempts, max_attempts, visible_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		job.Kind,
+		string(job.Payload),
+		job.Status,
+		job.Attempts,
+		job.MaxAttempts,
+		job.VisibleAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	).Scan(&job.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Claim takes the next visible job with attempts left for a worker until
+// visibleAt. It returns nil when there is nothing to do.
+func (r *QueueRepository) Claim(worker string, now, visibleAt time.Time) (*QueueJob, error) {
+	query := `
+	UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_by = ?, visible_at = ?, updated_at = ?
+	WHERE id = (
+		SELECT id FROM jobs
+		WHERE status IN ('queued', 'running') AND visible_at <= ? AND attempts < max_attempts
+		ORDER BY visible_at, id
+		LIMIT 1
+	)
+	RETURNING ` + queueJobColumns
+
+	job, err := scanQueueJob(r.db.QueryRow(query, worker, visibleAt, now, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// Finish records the outcome of an attempt. It reports false when the worker
+// no longer holds the job because its visibility timeout expired.
+func (r *QueueRepository) Finish(job *QueueJob) (bool, error) {
+	query := `
+	UPDATE jobs SET status = ?, last_error = ?, visible_at = ?, locked_by = '', updated_at = ?, finished_at = ?
+	WHERE id = ? AND status = 'running' AND locked_by = ? AND attempts = ?`
+
+	result, err := r.db.Exec(
+		query,
+		job.Status,
+		job.LastError,
+		job.VisibleAt,
+		job.UpdatedAt,
+		job.FinishedAt,
+		job.ID,
+		job.LockedBy,
+		job.Attempts,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	finished, err := result.RowsAffected()
+	return finished == 1, err
+}
+
+// Bury marks running jobs whose last attempt timed out as dead and returns
+// how many there were
+func (r *QueueRepository) Bury(now time.Time) (int, error) {
+	query := `
+	UPDATE jobs SET status = 'dead', last_error = 'visibility timeout expired', locked_by = '', updated_at = ?, finished_at = ?
+	WHERE status = 'running' AND visible_at <= ? AND attempts >= max_attempts`
+
+	result, err := r.db.Exec(query, now, now, now)
+	if err != nil {
+		return 0, err
+	}
+
+	buried, err := result.RowsAffected()
+	return int(buried), err
+}
+
+// Retry queues a dead job again with fresh attempts. It reports false when
+// the job is not dead.
+func (r *QueueRepository) Retry(id int, now time.Time) (bool, error) {
+	query := `
+	UPDATE jobs SET status = 'queued', attempts = 0, visible_at = ?, updated_at = ?, finished_at = NULL
+	WHERE id = ? AND status = 'dead'`
+
+	result, err := r.db.Exec(query, now, now, id)
+	if err != nil {
+		return false, err
+	}
+
+	retried, err := result.RowsAffected()
+	return retried == 1, err
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Reminder delivery states. A reminder is claimed (sending) before it is
+// delivered, so that it is never delivered twice.
+const (
+	ReminderPending = "pending"
+	ReminderSending = "sending"
+	ReminderSent    = "sent"
+	ReminderSkipped = "skipped"
+	ReminderFailed  = "failed"
+)
+
+// Reminder is a notification about a task scheduled for a single user
+type Reminder struct {
+	ID     int `json:"id"`
+	TaskID int `json:"task_id"`
+	UserID int `json:"user_id"`
+	// BeforeDue is an offset like "1h" for reminders relative to the due date,
+	// empty for reminders at a fixed time
+	BeforeDue string `json:"before_due,omitempty"`
+	// RemindAt is nil while a relative reminder's task has no due date
+	RemindAt  *time.Time `json:"remind_at,omitempty"`
+	Status    string     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ReminderRepository handles DB operations for reminders
+type ReminderRepository struct {
+	db *sql.DB
+}
+
+// NewReminderRepository creates a new ReminderRepository
+func NewReminderRepository(db *sql.DB) *ReminderRepository {
+	return &ReminderRepository{
+		db: db,
+	}
+}
+
+// Initialize creates reminders table if it doesn't exist
+func (r *ReminderRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		before_due TEXT NOT NULL DEFAULT '',
+		remind_at DATETIME,
+		status TEXT NOT NULL DEFAULT 'pending',
+		error TEXT NOT NULL DEFAULT '',
+		sent_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_reminders_task ON reminders(task_id);
+	CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(status, remind_at);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const reminderColumns = `id, task_id, user_id, before_due, remind_at, status, error, sent_at, created_at`
+
+func scanReminder(s rowScanner) (*Reminder, error) {
+	var rm Reminder
+	err := s.Scan(&rm.ID, &rm.TaskID, &rm.UserID, &rm.BeforeDue, &rm.RemindAt, &rm.Status, &rm.Error, &rm.SentAt, &rm.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rm, nil
+}
+
+func (r *ReminderRepository) findAll(ctx context.Context, query string, args ...interface{}) ([]Reminder, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []Reminder{}
+	for rows.Next() {
+		rm, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *rm)
+	}
+
+	return reminders, rows.Err()
+}
+
+// FindByTask returns the reminders a user has on a task
+func (r *ReminderRepository) FindByTask(ctx context.Context, taskID, userID int) ([]Reminder, error) {
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = ? AND user_id = ? ORDER BY remind_at, id`
+	return r.findAll(ctx, query, taskID, userID)
+}
+
+// FindPendingRelative returns the undelivered reminders of a task that
+// depend on its due date
+func (r *ReminderRepository) FindPendingRelative(ctx context.Context, taskID int) ([]Reminder, error) {
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = ? AND status = 'pending' AND before_due != ''`
+	return r.findAll(ctx, query, taskID)
+}
+
+// FindDue returns the pending reminders whose time has come by now
+func (r *ReminderRepository) FindDue(ctx context.Context, now time.Time) ([]Reminder, error) {
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE status = 'pending' AND remind_at <= ? ORDER BY remind_at, id`
+	return r.findAll(ctx, query, now)
+}
+
+// FindByID returns a reminder of a task
+func (r *ReminderRepository) FindByID(taskID, id int) (*Reminder, error) {
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE task_id = ? AND id = ?`
+
+	rm, err := scanReminder(r.db.QueryRow(query, taskID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReminderNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return rm, nil
+}
+
+// NextAt returns the time of the earliest pending reminder, or nil when
+// nothing is scheduled
+func (r *ReminderRepository) NextAt(ctx context.Context) (*time.Time, error) {
+	query := `SELECT remind_at FROM reminders WHERE status = 'pending' AND remind_at IS NOT NULL ORDER BY remind_at LIMIT 1`
+
+	var next time.Time
+	err := r.db.QueryRowContext(ctx, query).Scan(&next)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &next, nil
+}
+
+// Create adds a new reminder
+func (r *ReminderRepository) Create(reminder *Reminder) (*Reminder, error) {
+	query := `
+	INSERT INTO reminders (task_id, user_id, before_due, remind_at, status, error, sent_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		reminder.TaskID,
+		reminder.UserID,
+		reminder.BeforeDue,
+		reminder.RemindAt,
+		reminder.Status,
+		reminder.Error,
+		reminder.SentAt,
+		reminder.CreatedAt,
+	).Scan(&reminder.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+// Reschedule moves a pending reminder to another time
+func (r *ReminderRepository) Reschedule(ctx context.Context, id int, remindAt *time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE reminders SET remind_at = ? WHERE id = ? AND status = 'pending'`, remindAt, id)
+	return err
+}
+
+// Claim marks a pending reminder as being delivered. It reports false when
+// the reminder is no longer pending, e.g. because another dispatcher
+// claimed it first.
+func (r *ReminderRepository) Claim(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE reminders SET status = 'sending' WHERE id = ? AND status = 'pending'`, id)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := result.RowsAffected()
+	return claimed == 1, err
+}
+
+// Finish records the outcome of delivering a claimed reminder
+func (r *ReminderRepository) Finish(ctx context.Context, id int, status, message string, sentAt *time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE reminders SET status = ?, error = ?, sent_at = ? WHERE id = ?`, status, message, sentAt, id)
+	return err
+}
+
+// Delete removes a reminder
+func (r *ReminderRepository) Delete(id int) error {
+	query := `DELETE FROM reminders WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
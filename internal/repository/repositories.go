@@ -0,0 +1,81 @@
+package repository
+
+import "database/sql"
+
+// Repositories bundles all repositories sharing a database connection
+type Repositories struct {
+	Tasks         *TaskRepository
+	Users         *UserRepository
+	Workspaces    *WorkspaceRepository
+	History       *HistoryRepository
+	Notifications *NotificationRepository
+	Tokens        *TokenRepository
+	Comments      *CommentRepository
+	Attachments   *AttachmentRepository
+	Projects      *ProjectRepository
+	TimeEntries   *TimeEntryRepository
+	Checklists    *ChecklistRepository
+	Boards        *BoardRepository
+	Templates     *TemplateRepository
+	Reminders     *ReminderRepository
+	Digests       *DigestRepository
+	Leases        *LeaseRepository
+	Jobs          *JobRepository
+	Queue         *QueueRepository
+}
+
+// NewRepositories creates all repositories for the given database
+func NewRepositories(db *sql.DB) *Repositories {
+	return &Repositories{
+		Tasks:         NewTaskRepository(db),
+		Users:         NewUserRepository(db),
+		Workspaces:    NewWorkspaceRepository(db),
+		History:       NewHistoryRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Tokens:        NewTokenRepository(db),
+		Comments:      NewCommentRepository(db),
+		Attachments:   NewAttachmentRepository(db),
+		Projects:      NewProjectRepository(db),
+		TimeEntries:   NewTimeEntryRepository(db),
+		Checklists:    NewChecklistRepository(db),
+		Boards:        NewBoardRepository(db),
+		Templates:     NewTemplateRepository(db),
+		Reminders:     NewReminderRepository(db),
+		Digests:       NewDigestRepository(db),
+		Leases:        NewLeaseRepository(db),
+		Jobs:          NewJobRepository(db),
+		Queue:         NewQueueRepository(db),
+	}
+}
+
+// Initialize creates the tables of every repository, in dependency order
+func (r *Repositories) Initialize() error {
+	initializers := []func() error{
+		r.Users.Initialize,
+		r.Workspaces.Initialize,
+		r.Projects.Initialize,
+		r.Tasks.Initialize,
+		r.History.Initialize,
+		r.Notifications.Initialize,
+		r.Tokens.Initialize,
+		r.Comments.Initialize,
+		r.Attachments.Initialize,
+		r.TimeEntries.Initialize,
+		r.Checklists.Initialize,
+		r.Boards.Initialize,
+		r.Templates.Initialize,
+		r.Reminders.Initialize,
+		r.Digests.Initialize,
+		r.Leases.Initialize,
+		r.Jobs.Initialize,
+		r.Queue.Initialize,
+	}
+
+	for _, initialize := range initializers {
+		if err := initialize(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"golang_task_manager_folder_structure/internal/rank"
 )
 
 // Task represents a task entity
@@ -12,10 +16,61 @@ type Task struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Completed   bool       `json:"completed"`
+	Priority    string     `json:"priority"`
+	Status      string     `json:"status"`
+	Rank        string     `json:"rank"`
+	Tags        StringList `json:"tags"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	CreatorID   *int       `json:"creator_id,omitempty"`
+	WorkspaceID *int       `json:"workspace_id,omitempty"`
+	AssigneeID  *int       `json:"assignee_id,omitempty"`
+	ProjectID   *int       `json:"project_id,omitempty"`
+	ParentID    *int       `json:"parent_id,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// EstimateMinutes is the planned effort, compared against tracked time
+	EstimateMinutes *int `json:"estimate_minutes,omitempty"`
+
+	// HiddenUntil defers the task: lists leave it out until then
+	HiddenUntil *time.Time `json:"hidden_until,omitempty"`
+
+	// CommentCount and Checklist are computed when reading and ignored on writes
+	CommentCount int               `json:"comment_count"`
+	Checklist    ChecklistProgress `json:"checklist"`
+}
+
+// ChecklistProgress summarizes the checked items of a task's checklist
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+	// Progress is the fraction of checked items, 0 for an empty checklist
+	Progress float64 `json:"progress"`
+}
+
+// taskColumns lists the task columns in the order expected by scanTask
+const taskColumns = `id, title, description, completed, priority, status, rank, tags, recurrence, creator_id, workspace_id, assignee_id, project_id, parent_id, estimate_minutes, hidden_until, due_date, completed_at, created_at, updated_at,
+	(SELECT COUNT(*) FROM comments WHERE comments.task_id = tasks.id) AS comment_count,
+	(SELECT COUNT(*) FROM checklist_items WHERE checklist_items.task_id = tasks.id AND checked) AS checklist_done,
+	(SELECT COUNT(*) FROM checklist_items WHERE checklist_items.task_id = tasks.id) AS checklist_total`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(s rowScanner) (*Task, error) {
+	var t Task
+	err := s.Scan(&t.ID, &t.Title, &t.Description, &t.Completed, &t.Priority, &t.Status, &t.Rank, &t.Tags, &t.Recurrence, &t.CreatorID, &t.WorkspaceID, &t.AssigneeID, &t.ProjectID, &t.ParentID, &t.EstimateMinutes, &t.HiddenUntil, &t.DueDate, &t.CompletedAt, &t.CreatedAt, &t.UpdatedAt, &t.CommentCount, &t.Checklist.Done, &t.Checklist.Total)
+	if err != nil {
+		return nil, err
+	}
+	if t.Checklist.Total > 0 {
+		t.Checklist.Progress = float64(t.Checklist.Done) / float64(t.Checklist.Total)
+	}
+	return &t, nil
 }
 
 // TaskRepository handles DB operations for tasks
@@ -38,110 +93,579 @@ func (r *TaskRepository) Initialize() error {
 		title TEXT NOT NULL,
 		description TEXT,
 		completed BOOLEAN DEFAULT FALSE,
+		priority TEXT NOT NULL DEFAULT 'normal',
+		status TEXT NOT NULL DEFAULT 'todo',
+		rank TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '',
+		recurrence TEXT NOT NULL DEFAULT '',
+		creator_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+		assignee_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL,
+		parent_id INTEGER REFERENCES tasks(id) ON DELETE CASCADE,
+		estimate_minutes INTEGER,
+		hidden_until DATETIME,
 		due_date DATETIME,
 		completed_at DATETIME,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);`
 	
-	_, err := r.db.Exec(query)
-	return err
+	if _, err := r.db.Exec(query); err != nil {
+		return err
+	}
+
+	// Columns added after the initial schema
+	columns := []struct{ name, definition string }{
+		{"priority", "TEXT NOT NULL DEFAULT 'normal'"},
+		{"tags", "TEXT NOT NULL DEFAULT ''"},
+		{"recurrence", "TEXT NOT NULL DEFAULT ''"},
+		{"creator_id", "INTEGER REFERENCES users(id) ON DELETE SET NULL"},
+		{"workspace_id", "INTEGER REFERENCES workspaces(id) ON DELETE CASCADE"},
+		{"assignee_id", "INTEGER REFERENCES users(id) ON DELETE SET NULL"},
+		{"project_id", "INTEGER REFERENCES projects(id) ON DELETE SET NULL"},
+		{"estimate_minutes", "INTEGER"},
+		{"status", "TEXT NOT NULL DEFAULT 'todo'"},
+		{"rank", "TEXT NOT NULL DEFAULT ''"},
+		{"parent_id", "INTEGER REFERENCES tasks(id) ON DELETE CASCADE"},
+		{"hidden_until", "DATETIME"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(r.db, "tasks", c.name, c.definition); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.db.Exec(`UPDATE tasks SET status = 'done' WHERE completed AND status = 'todo'`); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_rank ON tasks(rank, id)`); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_hidden_until ON tasks(hidden_until)`); err != nil {
+		return err
+	}
+
+	return r.backfillRanks()
 }
 
-// FindAll returns all tasks
-func (r *TaskRepository) FindAll() ([]Task, error) {
-	query := `SELECT id, title, description, completed, due_date, completed_at, created_at, updated_at FROM tasks ORDER BY created_at DESC`
+// backfillRanks ranks tasks created before manual ordering existed, keeping
+// the newest-first order they used to be listed in
+func (r *TaskRepository) backfillRanks() error {
+	rows, err := r.db.Query(`SELECT id FROM tasks WHERE rank = '' ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Ranked tasks, if any, were created later and therefore come first
+	var last string
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(rank), '') FROM tasks`).Scan(&last); err != nil {
+		return err
+	}
+
+	for i, key := range rank.Spread(len(ids)) {
+		if last != "" {
+			key = last + key
+		}
+		if _, err := tx.Exec(`UPDATE tasks SET rank = ? WHERE id = ?`, key, ids[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TaskFilter narrows the tasks returned by FindAll. Zero values match everything.
+type TaskFilter struct {
+	AssigneeID  *int
+	WorkspaceID *int
+	ProjectID   *int
+	ParentID    *int
+	Status      *string
+	// Personal limits the result to tasks outside of any workspace
+	Personal bool
+	// VisibleTo limits the result to tasks in the user's workspaces and
+	// personal tasks they created or are assigned to
+	VisibleTo *int
+	// Open limits the result to tasks that are not completed
+	Open bool
+	// CompletedSince limits the result to tasks completed at or after that time
+	CompletedSince *time.Time
+	// DueBefore limits the result to tasks due before that time
+	DueBefore *time.Time
+	// AvailableAt leaves out tasks deferred beyond that time
+	AvailableAt *time.Time
+	// WokenBy limits the result to deferred tasks whose hidden_until has passed by then
+	WokenBy *time.Time
+	// OrderByRank sorts by the manual order instead of newest first
+	OrderByRank bool
+}
+
+// where returns the SQL conditions and arguments of the filter
+func (filter TaskFilter) where() ([]string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if filter.AssigneeID != nil {
+		conditions = append(conditions, "assignee_id = ?")
+		args = append(args, *filter.AssigneeID)
+	}
+	if filter.WorkspaceID != nil {
+		conditions = append(conditions, "workspace_id = ?")
+		args = append(args, *filter.WorkspaceID)
+	}
+	if filter.ProjectID != nil {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, *filter.ProjectID)
+	}
+	if filter.ParentID != nil {
+		conditions = append(conditions, "parent_id = ?")
+		args = append(args, *filter.ParentID)
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, *filter.Status)
+	}
+	if filter.Open {
+		conditions = append(conditions, "NOT completed")
+	}
+	if filter.CompletedSince != nil {
+		conditions = append(conditions, "completed AND completed_at >= ?")
+		args = append(args, *filter.CompletedSince)
+	}
+	if filter.DueBefore != nil {
+		conditions = append(conditions, "due_date < ?")
+		args = append(args, *filter.DueBefore)
+	}
+	if filter.AvailableAt != nil {
+		conditions = append(conditions, "(hidden_until IS NULL OR hidden_until <= ?)")
+		args = append(args, *filter.AvailableAt)
+	}
+	if filter.WokenBy != nil {
+		conditions = append(conditions, "hidden_until <= ?")
+		args = append(args, *filter.WokenBy)
+	}
+	if filter.Personal {
+		conditions = append(conditions, "workspace_id IS NULL")
+	}
+	if filter.VisibleTo != nil {
+		conditions = append(conditions, `(workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?)
+			OR (workspace_id IS NULL AND (creator_id = ? OR assignee_id = ?)))`)
+		args = append(args, *filter.VisibleTo, *filter.VisibleTo, *filter.VisibleTo)
+	}
+
+	return conditions, args
+}
+
+// FindAll returns all tasks matching the filter
+func (r *TaskRepository) FindAll(ctx context.Context, filter TaskFilter) (tasks []Task, err error) {
+	conditions, args := filter.where()
+
+	query := `SELECT ` + taskColumns + ` FROM tasks`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	if filter.OrderByRank {
+		query += ` ORDER BY rank, id`
+	} else {
+		query += ` ORDER BY created_at DESC`
+	}
+
+	ctx, span := startSpan(ctx, "TaskRepository.FindAll", query)
+	defer func() { endSpan(span, err) }()
 	
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	
-	var tasks []Task
-	
 	for rows.Next() {
-		var t Task
-		err := rows.Scan(&t.ID, &t.Title, &t.Description, &t.Completed, &t.DueDate, &t.CompletedAt, &t.CreatedAt, &t.UpdatedAt)
+		t, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, t)
+		tasks = append(tasks, *t)
 	}
 	
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 // FindByID returns a task by ID
-func (r *TaskRepository) FindByID(id int) (*Task, error) {
-	query := `SELECT id, title, description, completed, due_date, completed_at, created_at, updated_at FROM tasks WHERE id = ?`
-	
-	var t Task
-	err := r.db.QueryRow(query, id).Scan(&t.ID, &t.Title, &t.Description, &t.Completed, &t.DueDate, &t.CompletedAt, &t.CreatedAt, &t.UpdatedAt)
+func (r *TaskRepository) FindByID(ctx context.Context, id int) (*Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = ?`
+
+	ctx, span := startSpan(ctx, "TaskRepository.FindByID", query)
+	t, err := scanTask(r.db.QueryRowContext(ctx, query, id))
+	endSpan(span, err)
 	
 	if err == sql.ErrNoRows {
-		return nil, errors.New("task not found")
+		return nil, ErrTaskNotFound
 	} else if err != nil {
 		return nil, err
 	}
 	
-	return &t, nil
+	return t, nil
+}
+
+// Neighbor returns the task ranked directly after (or before) task among
+// the tasks matching the filter, ignoring the task with ID exclude. A nil
+// task stands for the edge of the list: the first task is the one after
+// it and the last task the one before it.
+func (r *TaskRepository) Neighbor(ctx context.Context, filter TaskFilter, task *Task, after bool, exclude int) (*Task, error) {
+	conditions, args := filter.where()
+
+	if task != nil && after {
+		conditions = append(conditions, "(rank > ? OR (rank = ? AND id > ?))")
+		args = append(args, task.Rank, task.Rank, task.ID)
+	} else if task != nil {
+		conditions = append(conditions, "(rank < ? OR (rank = ? AND id < ?))")
+		args = append(args, task.Rank, task.Rank, task.ID)
+	}
+	conditions = append(conditions, "id != ?")
+	args = append(args, exclude)
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE ` + strings.Join(conditions, " AND ")
+	if after {
+		query += ` ORDER BY rank, id LIMIT 1`
+	} else {
+		query += ` ORDER BY rank DESC, id DESC LIMIT 1`
+	}
+
+	ctx, span := startSpan(ctx, "TaskRepository.Neighbor", query)
+	t, err := scanTask(r.db.QueryRowContext(ctx, query, args...))
+	endSpan(span, err)
+	if err == sql.ErrNoRows {
+		return nil, ErrTaskNotFound
+	}
+	return t, err
+}
+
+// Count returns the number of tasks matching the filter
+func (r *TaskRepository) Count(ctx context.Context, filter TaskFilter) (int, error) {
+	conditions, args := filter.where()
+
+	query := `SELECT COUNT(*) FROM tasks`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	ctx, span := startSpan(ctx, "TaskRepository.Count", query)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	endSpan(span, err)
+	return count, err
+}
+
+// LastRank returns the highest rank in use, or "" when no task is ranked
+func (r *TaskRepository) LastRank(ctx context.Context) (string, error) {
+	query := `SELECT COALESCE(MAX(rank), '') FROM tasks`
+
+	ctx, span := startSpan(ctx, "TaskRepository.LastRank", query)
+	var last string
+	err := r.db.QueryRowContext(ctx, query).Scan(&last)
+	endSpan(span, err)
+	return last, err
+}
+
+// TaskTree is a task to be created together with its subtasks
+type TaskTree struct {
+	Task     *Task
+	Subtasks []TaskTree
 }
 
 // Create adds a new task
-func (r *TaskRepository) Create(task *Task) (*Task, error) {
+func (r *TaskRepository) Create(ctx context.Context, task *Task) (*Task, error) {
+	if err := insertTask(ctx, r.db, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// CreateWithHistory adds a new task together with history entries about it
+// in a single transaction. The entries get the ID of the new task.
+func (r *TaskRepository) CreateWithHistory(ctx context.Context, task *Task, entries []TaskHistoryEntry) (*Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertTask(ctx, tx, task); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].TaskID = task.ID
+		if err := insertHistory(ctx, tx, &entries[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// CreateTrees adds tasks and their subtasks in a single transaction,
+// setting the parent of every subtask. Top-level tasks keep their ParentID.
+func (r *TaskRepository) CreateTrees(ctx context.Context, trees []TaskTree) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertTrees(ctx, tx, trees, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertTrees(ctx context.Context, tx *sql.Tx, trees []TaskTree, parentID *int) error {
+	for _, tree := range trees {
+		if parentID != nil {
+			tree.Task.ParentID = parentID
+		}
+		if err := insertTask(ctx, tx, tree.Task); err != nil {
+			return err
+		}
+		if err := insertTrees(ctx, tx, tree.Subtasks, &tree.Task.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryRower is implemented by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertTask(ctx context.Context, q queryRower, task *Task) error {
 	query := `
-	INSERT INTO tasks (title, description, completed, due_date, completed_at, created_at, updated_at)
-	VALUES (?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO tasks (title, description, completed, priority, status, rank, tags, recurrence, creator_id, workspace_id, assignee_id, project_id, parent_id, estimate_minutes, hidden_until, due_date, completed_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	RETURNING id`
-	
-	err := r.db.QueryRow(
+
+	ctx, span := startSpan(ctx, "TaskRepository.Create", query)
+	err := q.QueryRowContext(
+		ctx,
 		query,
 		task.Title,
 		task.Description,
 		task.Completed,
+		task.Priority,
+		task.Status,
+		task.Rank,
+		task.Tags,
+		task.Recurrence,
+		task.CreatorID,
+		task.WorkspaceID,
+		task.AssigneeID,
+		task.ProjectID,
+		task.ParentID,
+		task.EstimateMinutes,
+		task.HiddenUntil,
 		task.DueDate,
 		task.CompletedAt,
 		task.CreatedAt,
 		task.UpdatedAt,
 	).Scan(&task.ID)
-	
-	if err != nil {
+	endSpan(span, err)
+	return err
+}
+
+// Update modifies an existing task
+func (r *TaskRepository) Update(ctx context.Context, task *Task) (*Task, error) {
+	if _, err := r.update(ctx, r.db, "TaskRepository.Update", task, "", nil); err != nil {
 		return nil, err
 	}
-	
+
 	return task, nil
 }
 
-// Update modifies an existing task
-func (r *TaskRepository) Update(task *Task) (*Task, error) {
+// UpdateWithHistory updates several tasks and records history entries about
+// them in a single transaction, so that either all of the changes are stored
+// or none. It fails with ErrTaskNotFound if one of the tasks is gone.
+func (r *TaskRepository) UpdateWithHistory(ctx context.Context, tasks []Task, entries []TaskHistoryEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range tasks {
+		updated, err := r.update(ctx, tx, "TaskRepository.UpdateWithHistory", &tasks[i], "", nil)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrTaskNotFound
+		}
+	}
+	for i := range entries {
+		if err := insertHistory(ctx, tx, &entries[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateWithinLimit modifies an existing task only while fewer than limit
+// tasks match the filter, checking the count and updating in one statement
+// so that concurrent updates cannot exceed the limit. It reports whether
+// the task was updated.
+func (r *TaskRepository) UpdateWithinLimit(ctx context.Context, task *Task, filter TaskFilter, limit int) (bool, error) {
+	conditions, args := filter.where()
+
+	condition := `(SELECT COUNT(*) FROM tasks`
+	if len(conditions) > 0 {
+		condition += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	condition += `) < ?`
+	args = append(args, limit)
+
+	return r.update(ctx, r.db, "TaskRepository.UpdateWithinLimit", task, condition, args)
+}
+
+// update writes a task, optionally only when condition holds, and reports
+// whether a row was updated
+func (r *TaskRepository) update(ctx context.Context, q execer, name string, task *Task, condition string, conditionArgs []interface{}) (bool, error) {
 	query := `
 	UPDATE tasks 
-	SET title = ?, description = ?, completed = ?, due_date = ?, completed_at = ?, updated_at = ?
+	SET title = ?, description = ?, completed = ?, priority = ?, status = ?, rank = ?, tags = ?, recurrence = ?, workspace_id = ?, assignee_id = ?, project_id = ?, estimate_minutes = ?, hidden_until = ?, due_date = ?, completed_at = ?, updated_at = ?
 	WHERE id = ?`
-	
-	_, err := r.db.Exec(
-		query,
+	if condition != "" {
+		query += ` AND ` + condition
+	}
+
+	args := []interface{}{
 		task.Title,
 		task.Description,
 		task.Completed,
+		task.Priority,
+		task.Status,
+		task.Rank,
+		task.Tags,
+		task.Recurrence,
+		task.WorkspaceID,
+		task.AssigneeID,
+		task.ProjectID,
+		task.EstimateMinutes,
+		task.HiddenUntil,
 		task.DueDate,
 		task.CompletedAt,
 		task.UpdatedAt,
 		task.ID,
-	)
-	
+	}
+	args = append(args, conditionArgs...)
+
+	ctx, span := startSpan(ctx, name, query)
+	result, err := q.ExecContext(ctx, query, args...)
+	endSpan(span, err)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	
-	return task, nil
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Respread gives the tasks ranked from lower to upper, inclusive, new
+// evenly spaced ranks in their current order. It makes room where tasks
+// share a rank or are ranked too closely to place another task between them.
+func (r *TaskRepository) Respread(ctx context.Context, lower, upper string) (err error) {
+	query := `SELECT id FROM tasks WHERE rank >= ? AND rank <= ? ORDER BY rank, id`
+
+	ctx, span := startSpan(ctx, "TaskRepository.Respread", query)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, lower, upper)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return err
+	}
+
+	// The new ranks go between the closest ranks outside of the range
+	var before, after string
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(rank), '') FROM tasks WHERE rank < ?`, lower).Scan(&before); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MIN(rank), '') FROM tasks WHERE rank > ?`, upper).Scan(&after); err != nil {
+		return err
+	}
+
+	keys, ok := rank.SpreadBetween(before, after, len(ids))
+	if !ok {
+		return fmt.Errorf("no room to rank %d tasks between %q and %q", len(ids), before, after)
+	}
+
+	for i, key := range keys {
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET rank = ? WHERE id = ?`, key, ids[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // Delete removes a task
-func (r *TaskRepository) Delete(id int) error {
+func (r *TaskRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM tasks WHERE id = ?`
-	
-	_, err := r.db.Exec(query, id)
+
+	ctx, span := startSpan(ctx, "TaskRepository.Delete", query)
+	_, err := r.db.ExecContext(ctx, query, id)
+	endSpan(span, err)
 	return err
 }
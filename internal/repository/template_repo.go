@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TemplateTask describes a task created from a template. Title and
+// description may contain {{placeholders}}; DueOffset such as "3d", "-1w" or
+// "4h" is relative to the anchor date given when instantiating.
+type TemplateTask struct {
+	Title           string         `json:"title"`
+	Description     string         `json:"description,omitempty"`
+	Tags            []string       `json:"tags,omitempty"`
+	Priority        string         `json:"priority,omitempty"`
+	DueOffset       string         `json:"due_offset,omitempty"`
+	EstimateMinutes *int           `json:"estimate_minutes,omitempty"`
+	Subtasks        []TemplateTask `json:"subtasks,omitempty"`
+}
+
+// TemplateTasks is a task tree stored as a JSON column
+type TemplateTasks []TemplateTask
+
+// Value implements driver.Valuer
+func (t TemplateTasks) Value() (driver.Value, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (t *TemplateTasks) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case []byte:
+		return json.Unmarshal(v, t)
+	default:
+		return fmt.Errorf("unsupported type %T for template tasks", src)
+	}
+}
+
+// Template is a reusable tree of tasks, shared in a workspace or personal
+// to its owner
+type Template struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	WorkspaceID *int          `json:"workspace_id,omitempty"`
+	OwnerID     *int          `json:"owner_id,omitempty"`
+	Tasks       TemplateTasks `json:"tasks"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// TemplateRepository handles DB operations for task templates
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateRepository creates a new TemplateRepository
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{
+		db: db,
+	}
+}
+
+// Initialize creates templates table if it doesn't exist
+func (r *TemplateRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS templates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+		owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		tasks TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const templateColumns = `id, name, description, workspace_id, owner_id, tasks, created_at, updated_at`
+
+func scanTemplate(s rowScanner) (*Template, error) {
+	var t Template
+	err := s.Scan(&t.ID, &t.Name, &t.Description, &t.WorkspaceID, &t.OwnerID, &t.Tasks, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindVisibleTo returns the templates of the user's workspaces and their personal templates
+func (r *TemplateRepository) FindVisibleTo(userID int) ([]Template, error) {
+	query := `
+	SELECT ` + templateColumns + ` FROM templates
+	WHERE workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?)
+		OR (workspace_id IS NULL AND owner_id = ?)
+	ORDER BY name`
+
+	rows, err := r.db.Query(query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+
+	return templates, rows.Err()
+}
+
+// FindByID returns a template by ID
+func (r *TemplateRepository) FindByID(id int) (*Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM templates WHERE id = ?`
+
+	t, err := scanTemplate(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	return t, err
+}
+
+// Create adds a new template
+func (r *TemplateRepository) Create(template *Template) (*Template, error) {
+	query := `
+	INSERT INTO templates (name, description, workspace_id, owner_id, tasks, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		template.Name,
+		template.Description,
+		template.WorkspaceID,
+		template.OwnerID,
+		template.Tasks,
+		template.CreatedAt,
+		template.UpdatedAt,
+	).Scan(&template.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// Update modifies an existing template
+func (r *TemplateRepository) Update(template *Template) (*Template, error) {
+	query := `UPDATE templates SET name = ?, description = ?, tasks = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, template.Name, template.Description, template.Tasks, template.UpdatedAt, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// Delete removes a template
+func (r *TemplateRepository) Delete(id int) error {
+	query := `DELETE FROM templates WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// TimeEntry is a period of time spent on a task. Running timers have no EndedAt.
+type TimeEntry struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	UserID    int        `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Minutes returns the tracked duration, measuring running timers up to now
+func (e *TimeEntry) Minutes(now time.Time) int {
+	end := now
+	if e.EndedAt != nil {
+		end = *e.EndedAt
+	}
+	return int(end.Sub(e.StartedAt).Minutes())
+}
+
+// ReportEntry is a finished time entry together with the task details used to group reports
+type ReportEntry struct {
+	TimeEntry
+	TaskTitle   string
+	ProjectName *string
+	Tags        StringList
+}
+
+// ReportFilter selects the time entries of a report. Zero values match everything.
+type ReportFilter struct {
+	From      *time.Time
+	To        *time.Time
+	UserID    *int
+	ProjectID *int
+	// VisibleTo limits the report to tasks the user can see
+	VisibleTo *int
+}
+
+// TimeEntryRepository handles DB operations for time entries
+type TimeEntryRepository struct {
+	db *sql.DB
+}
+
+// NewTimeEntryRepository creates a new TimeEntryRepository
+func NewTimeEntryRepository(db *sql.DB) *TimeEntryRepository {
+	return &TimeEntryRepository{
+		db: db,
+	}
+}
+
+// Initialize creates time_entries table if it doesn't exist. The partial
+// unique index guarantees at most one running timer per user.
+func (r *TimeEntryRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS time_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_time_entries_task ON time_entries(task_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entries_running ON time_entries(user_id) WHERE ended_at IS NULL;`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const timeEntryColumns = `id, task_id, user_id, started_at, ended_at, note, created_at, updated_at`
+
+func scanTimeEntry(s rowScanner) (*TimeEntry, error) {
+	var e TimeEntry
+	err := s.Scan(&e.ID, &e.TaskID, &e.UserID, &e.StartedAt, &e.EndedAt, &e.Note, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// FindByTask returns the time entries of a task, oldest first
+func (r *TimeEntryRepository) FindByTask(taskID int) ([]TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE task_id = ? ORDER BY started_at, id`
+
+	rows, err := r.db.Query(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+
+	return entries, rows.Err()
+}
+
+// FindByID returns a time entry by ID
+func (r *TimeEntryRepository) FindByID(id int) (*TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE id = ?`
+
+	e, err := scanTimeEntry(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTimeEntryNotFound
+	}
+	return e, err
+}
+
+// FindRunning returns the running timer of a user
+func (r *TimeEntryRepository) FindRunning(userID int) (*TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE user_id = ? AND ended_at IS NULL`
+
+	e, err := scanTimeEntry(r.db.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrTimeEntryNotFound
+	}
+	return e, err
+}
+
+// FindForReport returns finished time entries with their task's project and tags
+func (r *TimeEntryRepository) FindForReport(filter ReportFilter) ([]ReportEntry, error) {
+	conditions := []string{"e.ended_at IS NOT NULL"}
+	var args []interface{}
+
+	if filter.From != nil {
+		conditions = append(conditions, "e.started_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "e.started_at < ?")
+		args = append(args, *filter.To)
+	}
+	if filter.UserID != nil {
+		conditions = append(conditions, "e.user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	if filter.ProjectID != nil {
+		conditions = append(conditions, "t.project_id = ?")
+		args = append(args, *filter.ProjectID)
+	}
+	if filter.VisibleTo != nil {
+		conditions = append(conditions, `(t.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?)
+			OR (t.workspace_id IS NULL AND (t.creator_id = ? OR t.assignee_id = ?)))`)
+		args = append(args, *filter.VisibleTo, *filter.VisibleTo, *filter.VisibleTo)
+	}
+
+	query := `
+	SELECT e.id, e.task_id, e.user_id, e.started_at, e.ended_at, e.note, e.created_at, e.updated_at,
+		t.title, p.name, t.tags
+	FROM time_entries e
+	JOIN tasks t ON t.id = e.task_id
+	LEFT JOIN projects p ON p.id = t.project_id
+	WHERE ` + strings.Join(conditions, " AND ") + `
+	ORDER BY e.started_at, e.id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ReportEntry{}
+	for rows.Next() {
+		var e ReportEntry
+		err := rows.Scan(&e.ID, &e.TaskID, &e.UserID, &e.StartedAt, &e.EndedAt, &e.Note, &e.CreatedAt, &e.UpdatedAt,
+			&e.TaskTitle, &e.ProjectName, &e.Tags)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Create adds a new time entry. Starting a second running entry for a user
+// fails with ErrTimerRunning.
+func (r *TimeEntryRepository) Create(entry *TimeEntry) (*TimeEntry, error) {
+	query := `
+	INSERT INTO time_entries (task_id, user_id, started_at, ended_at, note, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		entry.TaskID,
+		entry.UserID,
+		entry.StartedAt,
+		entry.EndedAt,
+		entry.Note,
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	).Scan(&entry.ID)
+
+	var sqliteErr sqlite3.Error
+	if entry.EndedAt == nil && errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		// idx_time_entries_running allows one running entry per user
+		return nil, ErrTimerRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Update modifies an existing time entry
+func (r *TimeEntryRepository) Update(entry *TimeEntry) (*TimeEntry, error) {
+	query := `UPDATE time_entries SET started_at = ?, ended_at = ?, note = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, entry.StartedAt, entry.EndedAt, entry.Note, entry.UpdatedAt, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Delete removes a time entry
+func (r *TimeEntryRepository) Delete(id int) error {
+	query := `DELETE FROM time_entries WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// APIToken is a personal access token. Only a hash of the secret is stored.
+type APIToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `json:"-"`
+	Scopes     StringList `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TokenRepository handles DB operations for API tokens
+type TokenRepository struct {
+	db *sql.DB
+}
+
+// NewTokenRepository creates a new TokenRepository
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+// Initialize creates api_tokens table if it doesn't exist
+func (r *TokenRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		scopes TEXT NOT NULL,
+		expires_at DATETIME NOT NULL,
+		last_used_at DATETIME,
+		revoked_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+const tokenColumns = `id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanToken(s rowScanner) (*APIToken, error) {
+	var t APIToken
+	err := s.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.TokenHash, &t.Scopes, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindByUser returns a user's tokens, newest first
+func (r *TokenRepository) FindByUser(userID int) ([]APIToken, error) {
+	query := `SELECT ` + tokenColumns + ` FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// FindByID returns a token by ID
+func (r *TokenRepository) FindByID(id int) (*APIToken, error) {
+	query := `SELECT ` + tokenColumns + ` FROM api_tokens WHERE id = ?`
+
+	t, err := scanToken(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTokenNotFound
+	}
+	return t, err
+}
+
+// FindByHash returns the token with the given secret hash
+func (r *TokenRepository) FindByHash(hash string) (*APIToken, error) {
+	query := `SELECT ` + tokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+
+	t, err := scanToken(r.db.QueryRow(query, hash))
+	if err == sql.ErrNoRows {
+		return nil, ErrTokenNotFound
+	}
+	return t, err
+}
+
+// Create stores a new token
+func (r *TokenRepository) Create(token *APIToken) (*APIToken, error) {
+	query := `
+	INSERT INTO api_tokens (user_id, name, prefix, token_hash, scopes, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		query,
+		token.UserID,
+		token.Name,
+		token.Prefix,
+		token.TokenHash,
+		token.Scopes,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Touch records that a token was used
+func (r *TokenRepository) Touch(id int, at time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, at, id)
+	return err
+}
+
+// Revoke marks a token as revoked
+func (r *TokenRepository) Revoke(id int, at time.Time) error {
+	query := `UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	_, err := r.db.Exec(query, at, id)
+	return err
+}
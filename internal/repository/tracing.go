@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("golang_task_manager_folder_structure/internal/repository")
+
+// dbSystem names the database in spans; NewDatabase only supports SQLite
+const dbSystem = "sqlite"
+
+// startSpan starts a client span for a SQL statement, named after the
+// repository method that runs it
+func startSpan(ctx context.Context, name, statement string) (context.Context, trace.Span) {
+	statement = strings.TrimSpace(statement)
+
+	var operation string
+	if fields := strings.Fields(statement); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", dbSystem),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		),
+	)
+}
+
+// endSpan records a failed statement on the span and ends it. Finding no
+// rows is not a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
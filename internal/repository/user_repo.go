@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// User represents an account that can sign in and own tasks
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserRepository handles DB operations for users
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+// Initialize creates users table if it doesn't exist
+func (r *UserRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// FindByID returns a user by ID
+func (r *UserRepository) FindByID(id int) (*User, error) {
+	query := `SELECT id, username, email, password_hash, created_at FROM users WHERE id = ?`
+	return r.findOne(query, id)
+}
+
+// FindByUsername returns a user by username
+func (r *UserRepository) FindByUsername(username string) (*User, error) {
+	query := `SELECT id, username, email, password_hash, created_at FROM users WHERE username = ?`
+	return r.findOne(query, username)
+}
+
+// FindAll returns all users
+func (r *UserRepository) FindAll() ([]User, error) {
+	query := `SELECT id, username, email, password_hash, created_at FROM users ORDER BY id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *UserRepository) findOne(query string, arg interface{}) (*User, error) {
+	var u User
+	err := r.db.QueryRow(query, arg).Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// Create adds a new user
+func (r *UserRepository) Create(user *User) (*User, error) {
+	query := `
+	INSERT INTO users (username, email, password_hash, created_at)
+	VALUES (?, ?, ?, ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(query, user.Username, user.Email, user.PasswordHash, user.CreatedAt).Scan(&user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
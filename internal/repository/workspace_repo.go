@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Workspace groups users and the tasks they share
+type Workspace struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkspaceMember is a user's membership in a workspace
+type WorkspaceMember struct {
+	WorkspaceID int       `json:"workspace_id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WorkspaceRepository handles DB operations for workspaces and their members
+type WorkspaceRepository struct {
+	db *sql.DB
+}
+
+// NewWorkspaceRepository creates a new WorkspaceRepository
+func NewWorkspaceRepository(db *sql.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{
+		db: db,
+	}
+}
+
+// Initialize creates workspace tables if they don't exist
+func (r *WorkspaceRepository) Initialize() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS workspaces (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS workspace_members (
+		workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (workspace_id, user_id)
+	);`
+
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// Create adds a new workspace with its owner as the first member
+func (r *WorkspaceRepository) Create(workspace *Workspace, ownerID int, ownerRole string) (*Workspace, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO workspaces (name, created_at) VALUES (?, ?) RETURNING id`,
+		workspace.Name,
+		workspace.CreatedAt,
+	).Scan(&workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		workspace.ID,
+		ownerID,
+		ownerRole,
+		workspace.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// FindByID returns a workspace by ID
+func (r *WorkspaceRepository) FindByID(id int) (*Workspace, error) {
+	query := `SELECT id, name, created_at FROM workspaces WHERE id = ?`
+
+	var w Workspace
+	err := r.db.QueryRow(query, id).Scan(&w.ID, &w.Name, &w.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrWorkspaceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// FindByUser returns the workspaces a user is a member of
+func (r *WorkspaceRepository) FindByUser(userID int) ([]Workspace, error) {
+	query := `
+	SELECT w.id, w.name, w.created_at
+	FROM workspaces w
+	JOIN workspace_members m ON m.workspace_id = w.id
+	WHERE m.user_id = ?
+	ORDER BY w.name`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []Workspace{}
+	for rows.Next() {
+		var w Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// FindMembers returns the members of a workspace
+func (r *WorkspaceRepository) FindMembers(workspaceID int) ([]WorkspaceMember, error) {
+	query := `
+	SELECT m.workspace_id, m.user_id, u.username, m.role, m.created_at
+	FROM workspace_members m
+	JOIN users u ON u.id = m.user_id
+	WHERE m.workspace_id = ?
+	ORDER BY u.username`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []WorkspaceMember{}
+	for rows.Next() {
+		var m WorkspaceMember
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// FindMember returns a single membership
+func (r *WorkspaceRepository) FindMember(workspaceID, userID int) (*WorkspaceMember, error) {
+	query := `
+	SELECT m.workspace_id, m.user_id, u.username, m.role, m.created_at
+	FROM workspace_members m
+	JOIN users u ON u.id = m.user_id
+	WHERE m.workspace_id = ? AND m.user_id = ?`
+
+	var m WorkspaceMember
+	err := r.db.QueryRow(query, workspaceID, userID).Scan(&m.WorkspaceID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrMemberNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// SaveMember adds a member or updates the role of an existing one
+func (r *WorkspaceRepository) SaveMember(member *WorkspaceMember) error {
+	query := `
+	INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = excluded.role`
+
+	_, err := r.db.Exec(query, member.WorkspaceID, member.UserID, member.Role, member.CreatedAt)
+	return err
+}
+
+// RemoveMember removes a user from a workspace
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?`
+
+	_, err := r.db.Exec(query, workspaceID, userID)
+	return err
+}
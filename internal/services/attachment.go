@@ -0,0 +1,325 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+	"golang_task_manager_folder_structure/internal/storage"
+)
+
+// Upload errors
+var (
+	ErrAttachmentTooLarge  = errors.New("attachment exceeds the maximum size")
+	ErrUnsupportedFileType = errors.New("attachment type is not allowed")
+)
+
+// AttachmentLimits restricts what can be uploaded
+type AttachmentLimits struct {
+	MaxBytes int64
+	// AllowedTypes lists MIME types; "image/*" style wildcards are allowed
+	AllowedTypes []string
+}
+
+// AttachmentService handles business logic for task attachments
+type AttachmentService struct {
+	repo   *repository.AttachmentRepository
+	tasks  *repository.TaskRepository
+	policy *policy.Policy
+	blobs  storage.BlobStore
+	limits AttachmentLimits
+	logger *logger.Logger
+}
+
+// NewAttachmentService creates a new AttachmentService
+func NewAttachmentService(repos *repository.Repositories, policy *policy.Policy, blobs storage.BlobStore, limits AttachmentLimits, logger *logger.Logger) *AttachmentService {
+	return &AttachmentService{
+		repo:   repos.Attachments,
+		tasks:  repos.Tasks,
+		policy: policy,
+		blobs:  blobs,
+		limits: limits,
+		logger: logger,
+	}
+}
+
+// MaxBytes returns the maximum size of a single attachment
+func (s *AttachmentService) MaxBytes() int64 {
+	return s.limits.MaxBytes
+}
+
+// List returns the attachments of a task
+func (s *AttachmentService) List(ctx context.Context, actor policy.Actor, taskID int) ([]repository.Attachment, error) {
+	if err := s.checkTask(ctx, actor, taskID, policy.ViewTask); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByTask(taskID)
+}
+
+// Upload streams content into the blob store and records it as an
+// attachment of the task. The type is sniffed from the content rather than
+// trusted from the client.
+func (s *AttachmentService) Upload(ctx context.Context, actor policy.Actor, taskID int, filename string, content io.Reader) (*repository.Attachment, error) {
+	if err := s.checkTask(ctx, actor, taskID, policy.EditTask); err != nil {
+		return nil, err
+	}
+
+	filename = cleanFilename(filename)
+	if filename == "" {
+		return nil, invalid("filename is required")
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, invalid("file is empty")
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !s.allowed(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+
+	key, err := newStorageKey(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &limitedReader{r: io.MultiReader(bytes.NewReader(head), content), limit: s.limits.MaxBytes}
+	if err := s.blobs.Put(ctx, key, body, -1, contentType); err != nil {
+		if body.exceeded {
+			return nil, ErrAttachmentTooLarge
+		}
+		return nil, err
+	}
+
+	attachment, err := s.repo.Create(&repository.Attachment{
+		TaskID:      taskID,
+		UploaderID:  actor.ID(),
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        body.read,
+		StorageKey:  key,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		s.discard(ctx, key)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open returns an attachment together with its content. The caller closes the blob.
+func (s *AttachmentService) Open(ctx context.Context, actor policy.Actor, taskID, id int) (*repository.Attachment, storage.Blob, error) {
+	attachment, err := s.find(ctx, actor, taskID, id, policy.ViewTask)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := s.blobs.Get(ctx, attachment.StorageKey)
+	if err == storage.ErrBlobNotFound {
+		return nil, nil, repository.ErrAttachmentNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, blob, nil
+}
+
+// Delete removes an attachment and its content
+func (s *AttachmentService) Delete(ctx context.Context, actor policy.Actor, taskID, id int) error {
+	attachment, err := s.find(ctx, actor, taskID, id, policy.EditTask)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.discard(ctx, attachment.StorageKey)
+	return nil
+}
+
+// CopyTask copies every attachment of a task, including its content, to
+// another task. Authorization is left to the caller.
+func (s *AttachmentService) CopyTask(ctx context.Context, actor policy.Actor, fromTaskID, toTaskID int) error {
+	attachments, err := s.repo.FindByTask(fromTaskID)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		blob, err := s.blobs.Get(ctx, attachment.StorageKey)
+		if err == storage.ErrBlobNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		key, err := newStorageKey(toTaskID)
+		if err != nil {
+			blob.Close()
+			return err
+		}
+
+		err = s.blobs.Put(ctx, key, blob, attachment.Size, attachment.ContentType)
+		blob.Close()
+		if err != nil {
+			return err
+		}
+
+		_, err = s.repo.Create(&repository.Attachment{
+			TaskID:      toTaskID,
+			UploaderID:  actor.ID(),
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			StorageKey:  key,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			s.discard(ctx, key)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StorageKeys returns the keys of the stored content of every attachment of
+// a task. It is read before the task is deleted, since the attachment rows go
+// with the task; the content is removed afterwards with Discard.
+func (s *AttachmentService) StorageKeys(taskID int) ([]string, error) {
+	attachments, err := s.repo.FindByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey)
+	}
+	return keys, nil
+}
+
+// Discard removes stored content once no attachment refers to it anymore
+func (s *AttachmentService) Discard(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		s.discard(ctx, key)
+	}
+}
+
+// discard removes stored content that no attachment refers to. If that
+// fails the blob is orphaned, which is logged so that it can be cleaned up.
+func (s *AttachmentService) discard(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		logger.FromContext(ctx, s.logger).Error("Failed to delete an orphaned attachment blob", err, "storage_key", key)
+	}
+}
+
+func (s *AttachmentService) checkTask(ctx context.Context, actor policy.Actor, taskID int, action policy.Action) error {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return s.policy.Task(actor, task, action)
+}
+
+func (s *AttachmentService) find(ctx context.Context, actor policy.Actor, taskID, id int, action policy.Action) (*repository.Attachment, error) {
+	if err := s.checkTask(ctx, actor, taskID, action); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.TaskID != taskID {
+		return nil, repository.ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	for _, allowed := range s.limits.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedReader fails once more than limit bytes have been read
+type limitedReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		l.exceeded = true
+		return n, ErrAttachmentTooLarge
+	}
+	return n, err
+}
+
+// cleanFilename keeps the base name of an uploaded file and drops control characters
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 255 {
+		// Cut on a character boundary so the name stays valid UTF-8
+		cut := 255
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut]
+	}
+	return strings.TrimSpace(name)
+}
+
+// newStorageKey returns a random key; user supplied names never reach the store
+func newStorageKey(taskID int) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\alice\notes.txt`, "notes.txt"},
+		{"bad\x00name\n.txt", "badname.txt"},
+		{"/", ""},
+		{strings.Repeat("a", 300), strings.Repeat("a", 255)},
+		// 254 bytes followed by a two-byte character that does not fit
+		{strings.Repeat("a", 254) + "é.txt", strings.Repeat("a", 254)},
+	}
+
+	for _, tt := range tests {
+		got := cleanFilename(tt.input)
+		if got != tt.want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("cleanFilename(%q) = %q is not valid UTF-8", tt.input, got)
+		}
+	}
+}
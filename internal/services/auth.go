@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenTTL is how long a login token stays valid
+const tokenTTL = 24 * time.Hour
+
+// ErrInvalidCredentials is returned when a login or token cannot be verified
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// AuthService handles user registration and authentication
+type AuthService struct {
+	users  *repository.UserRepository
+	secret []byte
+}
+
+// NewAuthService creates a new AuthService
+func NewAuthService(users *repository.UserRepository, secret string) *AuthService {
+	return &AuthService{
+		users:  users,
+		secret: []byte(secret),
+	}
+}
+
+// Register creates a new user account
+func (s *AuthService) Register(username, email, password string) (*repository.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" || strings.ContainsAny(username, " @#!") {
+		return nil, invalid("username must be non-empty and cannot contain spaces, @, # or !")
+	}
+	if !strings.Contains(email, "@") {
+		return nil, invalid("a valid email is required")
+	}
+	if len(password) < 8 {
+		return nil, invalid("password must be at least 8 characters")
+	}
+
+	if _, err := s.users.FindByUsername(username); err == nil {
+		return nil, invalid("username is already taken")
+	} else if err != repository.ErrUserNotFound {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.users.Create(&repository.User{
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	})
+}
+
+// Login verifies credentials and returns a signed JWT
+func (s *AuthService) Login(username, password string) (string, *repository.User, error) {
+	user, err := s.users.FindByUsername(username)
+	if err == repository.ErrUserNotFound {
+		return "", nil, ErrInvalidCredentials
+	} else if err != nil {
+		return "", nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(user.ID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+	})
+
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, user, nil
+}
+
+// Authenticate validates a JWT and returns the user it was issued to
+func (s *AuthService) Authenticate(tokenString string) (*repository.User, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.users.FindByID(id)
+	if err == repository.ErrUserNotFound {
+		return nil, ErrInvalidCredentials
+	}
+	return user, err
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/rank"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+var statusPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// defaultColumns are used for boards created without a column configuration
+var defaultColumns = []repository.BoardColumn{
+	{Name: "To do", Status: StatusTodo},
+	{Name: "In progress", Status: StatusInProgress},
+	{Name: "Done", Status: StatusDone},
+}
+
+// BoardInput holds the fields of a new board. Without a project or
+// workspace the board shows the personal tasks of its creator.
+type BoardInput struct {
+	Name        string
+	WorkspaceID *int
+	ProjectID   *int
+	Columns     []repository.BoardColumn
+}
+
+// MoveInput places a task in a board column, directly after the task
+// AfterID or before the task BeforeID. Without a neighbor the task goes to
+// the bottom of the column.
+type MoveInput struct {
+	ColumnID int
+	AfterID  *int
+	BeforeID *int
+}
+
+// BoardView is a board together with the tasks of each column in manual order
+type BoardView struct {
+	Board   *repository.Board `json:"board"`
+	Columns []ColumnView      `json:"columns"`
+}
+
+// ColumnView is a board column with its tasks
+type ColumnView struct {
+	repository.BoardColumn
+	Tasks []repository.Task `json:"tasks"`
+}
+
+// BoardService handles business logic for Kanban boards
+type BoardService struct {
+	repo     *repository.BoardRepository
+	tasks    *repository.TaskRepository
+	projects *repository.ProjectRepository
+	history  *repository.HistoryRepository
+	policy   *policy.Policy
+}
+
+// NewBoardService creates a new BoardService
+func NewBoardService(repos *repository.Repositories, policy *policy.Policy) *BoardService {
+	return &BoardService{
+		repo:     repos.Boards,
+		tasks:    repos.Tasks,
+		projects: repos.Projects,
+		history:  repos.History,
+		policy:   policy,
+	}
+}
+
+// Create adds a new board
+func (s *BoardService) Create(actor policy.Actor, input BoardInput) (*repository.Board, error) {
+	if input.Name == "" {
+		return nil, invalid("name is required")
+	}
+	if actor.System {
+		return nil, invalid("boards must be created by a user")
+	}
+
+	board := &repository.Board{
+		Name:        input.Name,
+		WorkspaceID: input.WorkspaceID,
+		ProjectID:   input.ProjectID,
+		OwnerID:     actor.ID(),
+		CreatedAt:   time.Now(),
+		Columns:     input.Columns,
+	}
+
+	if board.ProjectID != nil {
+		project, err := s.projects.FindByID(*board.ProjectID)
+		if err == repository.ErrProjectNotFound {
+			return nil, invalid("unknown project")
+		} else if err != nil {
+			return nil, err
+		}
+		if board.WorkspaceID != nil && !sameUser(board.WorkspaceID, project.WorkspaceID) {
+			return nil, invalid("the project does not belong to the board's workspace")
+		}
+		board.WorkspaceID = project.WorkspaceID
+	}
+
+	if err := s.authorize(actor, board, policy.ManageBoards); err != nil {
+		return nil, err
+	}
+
+	if len(board.Columns) == 0 {
+		board.Columns = append([]repository.BoardColumn(nil), defaultColumns...)
+	}
+	if err := checkColumns(board.Columns); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(board)
+}
+
+// List returns the boards the actor can see
+func (s *BoardService) List(actor policy.Actor) ([]repository.Board, error) {
+	return s.repo.FindVisibleTo(actor.UserID)
+}
+
+// Get returns a board with the tasks of each column
+func (s *BoardService) Get(ctx context.Context, actor policy.Actor, id int) (*BoardView, error) {
+	board, err := s.find(actor, id, policy.ViewTask)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snoozed tasks stay off the board until they wake up
+	now := time.Now().UTC()
+	view := &BoardView{Board: board, Columns: []ColumnView{}}
+	for _, column := range board.Columns {
+		filter := s.scope(board)
+		filter.Status = &column.Status
+		filter.OrderByRank = true
+		filter.AvailableAt = &now
+
+		tasks, err := s.tasks.FindAll(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if tasks == nil {
+			tasks = []repository.Task{}
+		}
+
+		view.Columns = append(view.Columns, ColumnView{BoardColumn: column, Tasks: tasks})
+	}
+
+	return view, nil
+}
+
+// SaveColumns reconfigures the columns of a board
+func (s *BoardService) SaveColumns(actor policy.Actor, id int, columns []repository.BoardColumn) (*repository.Board, error) {
+	if _, err := s.find(actor, id, policy.ManageBoards); err != nil {
+		return nil, err
+	}
+
+	if err := checkColumns(columns); err != nil {
+		return nil, err
+	}
+
+	board, err := s.repo.SaveColumns(id, columns)
+	if err == repository.ErrBoardColumnNotFound {
+		return nil, invalid("columns can only be updated on their own board")
+	}
+	return board, err
+}
+
+// Move places a task in a board column relative to its neighbors, changing
+// its status to the column's. Moving a task into a column that is at its
+// WIP limit fails with a conflict.
+func (s *BoardService) Move(ctx context.Context, actor policy.Actor, taskID int, input MoveInput) (*repository.Task, error) {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Task(actor, task, policy.EditTask); err != nil {
+		return nil, err
+	}
+
+	column, err := s.repo.FindColumn(input.ColumnID)
+	if err == repository.ErrBoardColumnNotFound {
+		return nil, invalid("unknown column")
+	} else if err != nil {
+		return nil, err
+	}
+
+	board, err := s.find(actor, column.BoardID, policy.ViewTask)
+	if err != nil {
+		return nil, err
+	}
+	if !onBoard(board, task) {
+		return nil, invalid(fmt.Sprintf("task #%d is not on board #%d", task.ID, board.ID))
+	}
+
+	filter := s.scope(board)
+	filter.Status = &column.Status
+
+	lower, upper, err := s.neighbors(ctx, board, column, filter, task, input)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := rank.Between(lower, upper)
+	if !ok && lower <= upper {
+		// The neighbors share a rank or are ranked too closely: spread them
+		// out and look them up again
+		if err := s.tasks.Respread(ctx, lower, upper); err != nil {
+			return nil, err
+		}
+		if lower, upper, err = s.neighbors(ctx, board, column, filter, task, input); err != nil {
+			return nil, err
+		}
+		key, ok = rank.Between(lower, upper)
+	}
+	if !ok {
+		return nil, conflict("the task cannot be placed between the given neighbors")
+	}
+
+	previous := task.Status
+	task.Rank = key
+	task.Status = column.Status
+	task.UpdatedAt = time.Now()
+	if task.Status == StatusDone && !task.Completed {
+		task.Completed = true
+		task.CompletedAt = &task.UpdatedAt
+	} else if task.Status != StatusDone && task.Completed {
+		task.Completed = false
+		task.CompletedAt = nil
+	}
+
+	// The WIP limit is checked by the update itself so that concurrent moves
+	// into the column cannot exceed it
+	if previous != column.Status && column.WIPLimit != nil {
+		updated, err := s.tasks.UpdateWithinLimit(ctx, task, filter, *column.WIPLimit)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, conflict(fmt.Sprintf("column %q is at its WIP limit of %d tasks", column.Name, *column.WIPLimit))
+		}
+	} else if _, err := s.tasks.Update(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if previous != task.Status {
+		_, err := s.history.Create(&repository.TaskHistoryEntry{
+			TaskID:    task.ID,
+			ActorID:   actor.ID(),
+			Action:    repository.HistoryStatusChanged,
+			OldValue:  previous,
+			NewValue:  task.Status,
+			CreatedAt: task.UpdatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return task, nil
+}
+
+// neighbors returns the ranks the moved task has to be placed between
+func (s *BoardService) neighbors(ctx context.Context, board *repository.Board, column *repository.BoardColumn, filter repository.TaskFilter, task *repository.Task, input MoveInput) (string, string, error) {
+	anchor := func(id int) (*repository.Task, error) {
+		if id == task.ID {
+			return nil, invalid("a task cannot be moved next to itself")
+		}
+		neighbor, err := s.tasks.FindByID(ctx, id)
+		if err == repository.ErrTaskNotFound {
+			return nil, invalid(fmt.Sprintf("unknown neighbor task #%d", id))
+		} else if err != nil {
+			return nil, err
+		}
+		if neighbor.Status != column.Status || !onBoard(board, neighbor) {
+			return nil, invalid(fmt.Sprintf("task #%d is not in column %q", id, column.Name))
+		}
+		return neighbor, nil
+	}
+	rankOf := func(t *repository.Task, err error) (string, error) {
+		if err == repository.ErrTaskNotFound {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		return t.Rank, nil
+	}
+
+	switch {
+	case input.AfterID != nil && input.BeforeID != nil:
+		after, err := anchor(*input.AfterID)
+		if err != nil {
+			return "", "", err
+		}
+		before, err := anchor(*input.BeforeID)
+		if err != nil {
+			return "", "", err
+		}
+		return after.Rank, before.Rank, nil
+	case input.AfterID != nil:
+		after, err := anchor(*input.AfterID)
+		if err != nil {
+			return "", "", err
+		}
+		upper, err := rankOf(s.tasks.Neighbor(ctx, filter, after, true, task.ID))
+		return after.Rank, upper, err
+	case input.BeforeID != nil:
+		before, err := anchor(*input.BeforeID)
+		if err != nil {
+			return "", "", err
+		}
+		lower, err := rankOf(s.tasks.Neighbor(ctx, filter, before, false, task.ID))
+		return lower, before.Rank, err
+	default:
+		lower, err := rankOf(s.tasks.Neighbor(ctx, filter, nil, false, task.ID))
+		return lower, "", err
+	}
+}
+
+// find loads a board and checks that the actor may perform action on it
+func (s *BoardService) find(actor policy.Actor, id int, action policy.Action) (*repository.Board, error) {
+	board, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(actor, board, action); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+// authorize checks an action on a board: workspace boards follow the
+// actor's role, personal boards belong to their owner
+func (s *BoardService) authorize(actor policy.Actor, board *repository.Board, action policy.Action) error {
+	if actor.System {
+		return nil
+	}
+
+	if board.ProjectID != nil {
+		project, err := s.projects.FindByID(*board.ProjectID)
+		if err != nil {
+			return err
+		}
+		return s.policy.Project(actor, project, action)
+	}
+
+	if board.WorkspaceID != nil {
+		return s.policy.Workspace(actor, *board.WorkspaceID, action)
+	}
+
+	if board.OwnerID != nil && *board.OwnerID == actor.UserID {
+		return nil
+	}
+
+	return &policy.ForbiddenError{
+		Action: action,
+		Reason: fmt.Sprintf("board #%d is a personal board of another user", board.ID),
+	}
+}
+
+// scope returns the filter selecting the tasks shown on a board
+func (s *BoardService) scope(board *repository.Board) repository.TaskFilter {
+	var filter repository.TaskFilter
+
+	switch {
+	case board.ProjectID != nil:
+		filter.ProjectID = board.ProjectID
+	case board.WorkspaceID != nil:
+		filter.WorkspaceID = board.WorkspaceID
+	default:
+		filter.Personal = true
+		filter.VisibleTo = board.OwnerID
+	}
+
+	return filter
+}
+
+// onBoard reports whether a task belongs to the board's scope
+func onBoard(board *repository.Board, task *repository.Task) bool {
+	switch {
+	case board.ProjectID != nil:
+		return sameUser(task.ProjectID, board.ProjectID)
+	case board.WorkspaceID != nil:
+		return sameUser(task.WorkspaceID, board.WorkspaceID)
+	default:
+		return task.WorkspaceID == nil && board.OwnerID != nil &&
+			(sameUser(task.CreatorID, board.OwnerID) || sameUser(task.AssigneeID, board.OwnerID))
+	}
+}
+
+func checkColumns(columns []repository.BoardColumn) error {
+	if len(columns) == 0 {
+		return invalid("a board needs at least one column")
+	}
+
+	statuses := map[string]bool{}
+	for _, c := range columns {
+		if c.Name == "" {
+			return invalid("column name is required")
+		}
+		if !statusPattern.MatchString(c.Status) {
+			return invalid(fmt.Sprintf("invalid status %q: use lowercase letters, digits and underscores", c.Status))
+		}
+		if statuses[c.Status] {
+			return invalid(fmt.Sprintf("status %q is mapped to more than one column", c.Status))
+		}
+		if c.WIPLimit != nil && *c.WIPLimit < 1 {
+			return invalid("wip_limit must be at least 1")
+		}
+		statuses[c.Status] = true
+	}
+
+	return nil
+}
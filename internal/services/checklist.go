@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// maxChecklistItemLength bounds the text of a checklist item
+const maxChecklistItemLength = 500
+
+// ChecklistService handles business logic for task checklists
+type ChecklistService struct {
+	repo   *repository.ChecklistRepository
+	tasks  *repository.TaskRepository
+	policy *policy.Policy
+}
+
+// NewChecklistService creates a new ChecklistService
+func NewChecklistService(repos *repository.Repositories, policy *policy.Policy) *ChecklistService {
+	return &ChecklistService{
+		repo:   repos.Checklists,
+		tasks:  repos.Tasks,
+		policy: policy,
+	}
+}
+
+// List returns the checklist of a task
+func (s *ChecklistService) List(ctx context.Context, actor policy.Actor, taskID int) ([]repository.ChecklistItem, error) {
+	if err := s.authorize(ctx, actor, taskID, policy.ViewTask); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByTask(taskID)
+}
+
+// Add appends an item to a task's checklist
+func (s *ChecklistService) Add(ctx context.Context, actor policy.Actor, taskID int, text string) (*repository.ChecklistItem, error) {
+	if err := checkItemText(text); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, actor, taskID, policy.EditTask); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(&repository.ChecklistItem{
+		TaskID:    taskID,
+		Text:      text,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Update changes the text (when not empty) and checked state (when not nil) of an item
+func (s *ChecklistService) Update(ctx context.Context, actor policy.Actor, taskID, id int, text string, checked *bool) (*repository.ChecklistItem, error) {
+	if err := s.authorize(ctx, actor, taskID, policy.EditTask); err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.FindByID(taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if text != "" {
+		if err := checkItemText(text); err != nil {
+			return nil, err
+		}
+		item.Text = text
+	}
+
+	if checked != nil && *checked != item.Checked {
+		item.Checked = *checked
+		item.CheckedAt = nil
+		if item.Checked {
+			now := time.Now()
+			item.CheckedAt = &now
+		}
+	}
+
+	return s.repo.Update(item)
+}
+
+// Toggle flips the checked state of an item
+func (s *ChecklistService) Toggle(ctx context.Context, actor policy.Actor, taskID, id int) (*repository.ChecklistItem, error) {
+	if err := s.authorize(ctx, actor, taskID, policy.EditTask); err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.FindByID(taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := !item.Checked
+	return s.Update(ctx, actor, taskID, id, "", &checked)
+}
+
+// Reorder puts a task's checklist in the order of ids, which must list
+// every item of the checklist exactly once
+func (s *ChecklistService) Reorder(ctx context.Context, actor policy.Actor, taskID int, ids []int) ([]repository.ChecklistItem, error) {
+	if err := s.authorize(ctx, actor, taskID, policy.EditTask); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.FindByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) != len(items) {
+		return nil, invalid("item_ids must list every checklist item exactly once")
+	}
+	remaining := make(map[int]bool, len(items))
+	for _, item := range items {
+		remaining[item.ID] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return nil, invalid("item_ids must list every checklist item exactly once")
+		}
+		delete(remaining, id)
+	}
+
+	if err := s.repo.Reorder(taskID, ids); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByTask(taskID)
+}
+
+// Delete removes an item from a task's checklist
+func (s *ChecklistService) Delete(ctx context.Context, actor policy.Actor, taskID, id int) error {
+	if err := s.authorize(ctx, actor, taskID, policy.EditTask); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.FindByID(taskID, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(id)
+}
+
+// authorize checks that the actor may perform action on the task
+func (s *ChecklistService) authorize(ctx context.Context, actor policy.Actor, taskID int, action policy.Action) error {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return s.policy.Task(actor, task, action)
+}
+
+func checkItemText(text string) error {
+	if text == "" {
+		return invalid("text is required")
+	}
+	if len(text) > maxChecklistItemLength {
+		return invalid("checklist items are limited to 500 characters")
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+
+	"github.com/yuin/goldmark"
+)
+
+// maxCommentLength bounds the size of a comment body
+const maxCommentLength = 10000
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.\-]+)`)
+
+// Activity is an entry of a task's activity thread: either a comment or a
+// history entry
+type Activity struct {
+	Type    string                       `json:"type"`
+	At      time.Time                    `json:"at"`
+	Comment *repository.Comment          `json:"comment,omitempty"`
+	Change  *repository.TaskHistoryEntry `json:"change,omitempty"`
+}
+
+// CommentService handles business logic for task comments
+type CommentService struct {
+	repo    *repository.CommentRepository
+	tasks   *repository.TaskRepository
+	users   *repository.UserRepository
+	history *repository.HistoryRepository
+	policy  *policy.Policy
+	queue   *QueueService
+	logger  *logger.Logger
+}
+
+// NewCommentService creates a new CommentService
+func NewCommentService(repos *repository.Repositories, policy *policy.Policy, queue *QueueService, logger *logger.Logger) *CommentService {
+	return &CommentService{
+		repo:    repos.Comments,
+		tasks:   repos.Tasks,
+		users:   repos.Users,
+		history: repos.History,
+		policy:  policy,
+		queue:   queue,
+		logger:  logger,
+	}
+}
+
+// List returns the comments of a task
+func (s *CommentService) List(ctx context.Context, actor policy.Actor, taskID int) ([]repository.Comment, error) {
+	if _, err := s.task(ctx, actor, taskID, policy.ViewTask); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.repo.FindByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range comments {
+		if err := render(&comments[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return comments, nil
+}
+
+// Create posts a comment on a task and notifies mentioned users
+func (s *CommentService) Create(ctx context.Context, actor policy.Actor, taskID int, body string) (*repository.Comment, error) {
+	task, err := s.task(ctx, actor, taskID, policy.CommentTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateBody(body); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.repo.Create(&repository.Comment{
+		TaskID:    taskID,
+		AuthorID:  actor.ID(),
+		Body:      body,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, actor, task, comment, nil)
+
+	return comment, render(comment)
+}
+
+// Update edits a comment. Only the author, or a moderator, may edit it.
+// Users mentioned for the first time are notified.
+func (s *CommentService) Update(ctx context.Context, actor policy.Actor, taskID, id int, body string) (*repository.Comment, error) {
+	task, comment, err := s.comment(ctx, actor, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateBody(body); err != nil {
+		return nil, err
+	}
+
+	previous := Mentions(comment.Body)
+	comment.Body = body
+	comment.UpdatedAt = time.Now()
+	if _, err := s.repo.Update(comment); err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(ctx, actor, task, comment, previous)
+
+	return comment, render(comment)
+}
+
+// Delete removes a comment. Only the author, or a moderator, may delete it.
+func (s *CommentService) Delete(ctx context.Context, actor policy.Actor, taskID, id int) error {
+	if _, _, err := s.comment(ctx, actor, taskID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Activity returns the comments and recorded changes of a task as a single
+// chronological thread
+func (s *CommentService) Activity(ctx context.Context, actor policy.Actor, taskID int) ([]Activity, error) {
+	comments, err := s.List(ctx, actor, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.history.FindByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]Activity, 0, len(comments)+len(changes))
+	for i := range comments {
+		activity = append(activity, Activity{Type: "comment", At: comments[i].CreatedAt, Comment: &comments[i]})
+	}
+	for i := range changes {
+		activity = append(activity, Activity{Type: "change", At: changes[i].CreatedAt, Change: &changes[i]})
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].At.Before(activity[j].At)
+	})
+
+	return activity, nil
+}
+
+// Mentions returns the distinct usernames @mentioned in a Markdown body
+func Mentions(body string) []string {
+	seen := map[string]bool{}
+	var usernames []string
+
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.TrimRight(m[1], ".-")
+		if username != "" && !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// notifyMentions queues notifications to users mentioned in the comment who
+// can see the task, skipping the author and anyone listed in alreadyNotified.
+// The comment is already saved, so failures are logged rather than returned;
+// a client retry would otherwise post the comment twice.
+func (s *CommentService) notifyMentions(ctx context.Context, actor policy.Actor, task *repository.Task, comment *repository.Comment, alreadyNotified []string) {
+	log := logger.FromContext(ctx, s.logger)
+
+	skip := map[string]bool{}
+	for _, username := range alreadyNotified {
+		skip[username] = true
+	}
+
+	for _, username := range Mentions(comment.Body) {
+		if skip[username] {
+			continue
+		}
+
+		user, err := s.users.FindByUsername(username)
+		if err == repository.ErrUserNotFound {
+			continue
+		} else if err != nil {
+			log.Error("Failed to look up a mentioned user", err, "comment_id", comment.ID, "username", username)
+			continue
+		}
+
+		if !actor.System && user.ID == actor.UserID {
+			continue
+		}
+		// Mentions never leak a task to users who cannot see it
+		if err := s.policy.Task(policy.User(user.ID), task, policy.ViewTask); err != nil {
+			continue
+		}
+
+		_, err = s.queue.Enqueue(QueueNotification, notify.Notification{
+			UserID:  user.ID,
+			TaskID:  &task.ID,
+			Subject: fmt.Sprintf("You were mentioned on task #%d", task.ID),
+			Body:    comment.Body,
+		})
+		if err != nil {
+			log.Error("Failed to queue the mention notification", err, "comment_id", comment.ID, "user_id", user.ID)
+		}
+	}
+}
+
+// task loads a task and checks that the actor may perform action on it
+func (s *CommentService) task(ctx context.Context, actor policy.Actor, taskID int, action policy.Action) (*repository.Task, error) {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Task(actor, task, action); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// comment loads a comment of a task that the actor may modify
+func (s *CommentService) comment(ctx context.Context, actor policy.Actor, taskID, id int) (*repository.Task, *repository.Comment, error) {
+	task, err := s.task(ctx, actor, taskID, policy.ViewTask)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comment, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if comment.TaskID != taskID {
+		return nil, nil, repository.ErrCommentNotFound
+	}
+
+	authored := comment.AuthorID != nil && !actor.System && *comment.AuthorID == actor.UserID
+	if !authored {
+		if err := s.policy.Task(actor, task, policy.ModerateComment); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return task, comment, nil
+}
+
+func validateBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return invalid("body is required")
+	}
+	if len(body) > maxCommentLength {
+		return invalid(fmt.Sprintf("body must be at most %d characters", maxCommentLength))
+	}
+	return nil
+}
+
+// render fills in the HTML rendering of a comment's Markdown body. Raw HTML
+// in the Markdown is not passed through.
+func render(comment *repository.Comment) error {
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(comment.Body), &buf); err != nil {
+		return err
+	}
+	comment.BodyHTML = buf.String()
+	return nil
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Digest frequencies
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+	DigestOff    = "off"
+)
+
+// defaultDigest applies to users who have not saved digest settings
+var defaultDigest = repository.DigestSettings{
+	Frequency: DigestDaily,
+	Hour:      8,
+	Weekday:   "monday",
+	Timezone:  "UTC",
+}
+
+// DigestInput holds the user supplied digest settings. Empty strings and
+// nil values leave the setting unchanged.
+type DigestInput struct {
+	Frequency string
+	Hour      *int
+	Weekday   string
+	Timezone  string
+}
+
+// Digest summarizes the tasks of a user since their previous digest
+type Digest struct {
+	User      repository.User
+	Frequency string
+	// Date is the user's local date the digest is for
+	Date time.Time
+	// Overdue, DueToday and DueThisWeek list open tasks assigned to the user
+	Overdue     []repository.Task
+	DueToday    []repository.Task
+	DueThisWeek []repository.Task
+	// CompletedByTeammates lists workspace tasks other members completed
+	// since the previous digest
+	CompletedByTeammates []repository.Task
+}
+
+// Empty reports whether the digest has nothing to tell
+func (d *Digest) Empty() bool {
+	return len(d.Overdue)+len(d.DueToday)+len(d.DueThisWeek)+len(d.CompletedByTeammates) == 0
+}
+
+// DigestService handles business logic for digest emails
+type DigestService struct {
+	repo  *repository.DigestRepository
+	users *repository.UserRepository
+	tasks *repository.TaskRepository
+}
+
+// NewDigestService creates a new DigestService
+func NewDigestService(repos *repository.Repositories) *DigestService {
+	return &DigestService{
+		repo:  repos.Digests,
+		users: repos.Users,
+		tasks: repos.Tasks,
+	}
+}
+
+// Settings returns the digest settings of the actor
+func (s *DigestService) Settings(actor policy.Actor) (*repository.DigestSettings, error) {
+	if actor.System {
+		return nil, invalid("digests belong to a user")
+	}
+	return s.settings(actor.UserID)
+}
+
+// SaveSettings changes when the actor receives digests
+func (s *DigestService) SaveSettings(actor policy.Actor, input DigestInput) (*repository.DigestSettings, error) {
+	settings, err := s.Settings(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Frequency != "" {
+		if input.Frequency != DigestDaily && input.Frequency != DigestWeekly && input.Frequency != DigestOff {
+			return nil, invalid("frequency must be daily, weekly or off")
+		}
+		settings.Frequency = input.Frequency
+	}
+
+	if input.Hour != nil {
+		if *input.Hour < 0 || *input.Hour > 23 {
+			return nil, invalid("hour must be between 0 and 23")
+		}
+		settings.Hour = *input.Hour
+	}
+
+	if input.Weekday != "" {
+		weekday, ok := weekdays[strings.ToLower(input.Weekday)]
+		if !ok {
+			return nil, invalid(fmt.Sprintf("unknown weekday %q", input.Weekday))
+		}
+		settings.Weekday = strings.ToLower(weekday.String())
+	}
+
+	if input.Timezone != "" {
+		if _, err := time.LoadLocation(input.Timezone); err != nil {
+			return nil, invalid(fmt.Sprintf("unknown timezone %q", input.Timezone))
+		}
+		settings.Timezone = input.Timezone
+	}
+
+	settings.UpdatedAt = time.Now()
+	return s.repo.Save(settings)
+}
+
+// Due builds the digests that are due by now: for every user with an email
+// address whose scheduled digest time has passed since their previous one
+func (s *DigestService) Due(ctx context.Context, now time.Time) ([]Digest, error) {
+	users, err := s.users.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var digests []Digest
+	for _, user := range users {
+		if user.Email == "" {
+			continue
+		}
+
+		settings, err := s.settings(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.Frequency == DigestOff {
+			continue
+		}
+
+		scheduled, period, err := lastScheduled(settings, now)
+		if err != nil {
+			return nil, err
+		}
+
+		// Users get their first digest at the first scheduled time after they signed up
+		since := user.CreatedAt
+		if settings.LastSentAt != nil {
+			since = *settings.LastSentAt
+		}
+		if !since.Before(scheduled) {
+			continue
+		}
+		if earliest := scheduled.Add(-period); since.Before(earliest) {
+			since = earliest
+		}
+
+		digest, err := s.build(ctx, user, settings, scheduled, since)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, *digest)
+	}
+
+	return digests, nil
+}
+
+// MarkSent records that a user's digest was handled at the given time
+func (s *DigestService) MarkSent(userID int, at time.Time) error {
+	settings, err := s.settings(userID)
+	if err != nil {
+		return err
+	}
+
+	settings.LastSentAt = &at
+	settings.UpdatedAt = at
+	_, err = s.repo.Save(settings)
+	return err
+}
+
+// build collects the tasks of a digest scheduled for a user's local time.
+// Tasks are bucketed by the user's calendar days: those due before the start
+// of today are overdue.
+func (s *DigestService) build(ctx context.Context, user repository.User, settings *repository.DigestSettings, scheduled, since time.Time) (*Digest, error) {
+	year, month, day := scheduled.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, scheduled.Location())
+	tomorrow := today.AddDate(0, 0, 1)
+	weekEnd := today.AddDate(0, 0, 8)
+
+	digest := &Digest{
+		User:      user,
+		Frequency: settings.Frequency,
+		Date:      today,
+	}
+
+	assigned, err := s.tasks.FindAll(ctx, repository.TaskFilter{AssigneeID: &user.ID, VisibleTo: &user.ID, Open: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range assigned {
+		if task.DueDate == nil {
+			continue
+		}
+		// Shown in the user's timezone as well
+		due := localDueDate(*task.DueDate, today.Location())
+		task.DueDate = &due
+
+		switch {
+		case task.DueDate.Before(today):
+			digest.Overdue = append(digest.Overdue, task)
+		case task.DueDate.Before(tomorrow):
+			digest.DueToday = append(digest.DueToday, task)
+		case task.DueDate.Before(weekEnd):
+			digest.DueThisWeek = append(digest.DueThisWeek, task)
+		}
+	}
+
+	since = since.UTC()
+	completed, err := s.tasks.FindAll(ctx, repository.TaskFilter{VisibleTo: &user.ID, CompletedSince: &since})
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range completed {
+		if task.WorkspaceID != nil && task.AssigneeID != nil && *task.AssigneeID != user.ID {
+			digest.CompletedByTeammates = append(digest.CompletedByTeammates, task)
+		}
+	}
+
+	return digest, nil
+}
+
+// settings returns the saved digest settings of a user or the defaults
+func (s *DigestService) settings(userID int) (*repository.DigestSettings, error) {
+	settings, err := s.repo.FindByUser(userID)
+	if err == repository.ErrDigestSettingsNotFound {
+		defaults := defaultDigest
+		defaults.UserID = userID
+		return &defaults, nil
+	}
+	return settings, err
+}
+
+// localDueDate returns a due date in the user's timezone. Due dates set as a
+// plain date are stored at midnight UTC and stand for that calendar day
+// wherever the user is, rather than for an instant.
+func localDueDate(due time.Time, location *time.Location) time.Time {
+	due = due.UTC()
+	if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 && due.Nanosecond() == 0 {
+		return time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, location)
+	}
+	return due.In(location)
+}
+
+// lastScheduled returns the most recent scheduled digest time at or before
+// now, in the user's timezone, and the time between two digests
+func lastScheduled(settings *repository.DigestSettings, now time.Time) (time.Time, time.Duration, error) {
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	local := now.In(location)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), settings.Hour, 0, 0, 0, location)
+
+	days := 1
+	if settings.Frequency == DigestWeekly {
+		days = 7
+		back := (int(local.Weekday()) - int(weekdays[settings.Weekday]) + 7) % 7
+		scheduled = scheduled.AddDate(0, 0, -back)
+	}
+	if scheduled.After(local) {
+		scheduled = scheduled.AddDate(0, 0, -days)
+	}
+
+	return scheduled, time.Duration(days) * 24 * time.Hour, nil
+}
@@ -0,0 +1,28 @@
+package services
+
+// ValidationError reports input that the service refused to act on
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// ConflictError reports a request that clashes with the current state, such
+// as starting a second timer
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+func conflict(message string) error {
+	return &ConflictError{Message: message}
+}
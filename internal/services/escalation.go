@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Escalation steps, recorded as the new value of HistoryEscalated entries
+const (
+	EscalateAssignee = "assignee"
+	EscalateOwner    = "project_owner"
+)
+
+// nextPriority is the priority an escalated task is bumped to
+var nextPriority = map[string]string{
+	PriorityLow:    PriorityNormal,
+	PriorityNormal: PriorityHigh,
+	PriorityHigh:   PriorityUrgent,
+}
+
+// EscalationPolicy configures how overdue tasks are escalated. A zero
+// duration disables the step.
+type EscalationPolicy struct {
+	// AssigneeAfter is how long a task is overdue before its assignee is
+	// notified again
+	AssigneeAfter time.Duration
+	// OwnerAfter is how long a task is overdue before the owner of its
+	// project, or the owners of its workspace, are notified
+	OwnerAfter time.Duration
+	// BumpPriority raises the priority of a task by one level when its
+	// owners are notified
+	BumpPriority bool
+}
+
+// EscalationService escalates overdue tasks
+type EscalationService struct {
+	tasks      *repository.TaskRepository
+	projects   *repository.ProjectRepository
+	workspaces *repository.WorkspaceRepository
+	history    *repository.HistoryRepository
+	notifier   notify.Notifier
+	policy     EscalationPolicy
+}
+
+// NewEscalationService creates a new EscalationService
+func NewEscalationService(repos *repository.Repositories, notifier notify.Notifier, policy EscalationPolicy) *EscalationService {
+	return &EscalationService{
+		tasks:      repos.Tasks,
+		projects:   repos.Projects,
+		workspaces: repos.Workspaces,
+		history:    repos.History,
+		notifier:   notifier,
+		policy:     policy,
+	}
+}
+
+// Escalate takes the escalation steps that are due for the open, overdue
+// tasks and returns how many were taken. A task is overdue once its due date
+// has passed. Steps are recorded in the task history together with the due
+// date before anyone is notified, so each step is taken at most once per due
+// date. A task that fails does not hold up the others; the errors are
+// returned together. When ctx is cancelled it stops before the next task.
+func (s *EscalationService) Escalate(ctx context.Context, now time.Time) (int, error) {
+	if s.policy.AssigneeAfter == 0 && s.policy.OwnerAfter == 0 {
+		return 0, nil
+	}
+
+	dueBefore := now.UTC()
+	tasks, err := s.tasks.FindAll(ctx, repository.TaskFilter{Open: true, DueBefore: &dueBefore})
+	if err != nil {
+		return 0, err
+	}
+
+	taken := 0
+	var errs []error
+	for i := range tasks {
+		if err := ctx.Err(); err != nil {
+			return taken, errors.Join(append(errs, err)...)
+		}
+
+		n, err := s.escalate(ctx, &tasks[i], now)
+		taken += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task #%d: %w", tasks[i].ID, err))
+		}
+	}
+
+	return taken, errors.Join(errs...)
+}
+
+// escalate takes the steps that are due for a single overdue task and
+// returns how many were taken
+func (s *EscalationService) escalate(ctx context.Context, task *repository.Task, now time.Time) (int, error) {
+	overdue := now.Sub(*task.DueDate)
+
+	done, err := s.steps(task)
+	if err != nil {
+		return 0, err
+	}
+
+	taken := 0
+	var errs []error
+	if s.policy.AssigneeAfter > 0 && overdue >= s.policy.AssigneeAfter && !done[EscalateAssignee] {
+		recorded, err := s.notifyAssignee(task)
+		if recorded {
+			taken++
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.policy.OwnerAfter > 0 && overdue >= s.policy.OwnerAfter && !done[EscalateOwner] {
+		recorded, err := s.notifyOwner(ctx, task)
+		if recorded {
+			taken++
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return taken, errors.Join(errs...)
+}
+
+// steps returns the escalation steps already taken for the current due date of a task
+func (s *EscalationService) steps(task *repository.Task) (map[string]bool, error) {
+	entries, err := s.history.FindByTask(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	due := formatTime(task.DueDate)
+	done := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Action == repository.HistoryEscalated && entry.OldValue == due {
+			done[entry.NewValue] = true
+		}
+	}
+	return done, nil
+}
+
+// notifyAssignee reminds the assignee (or, for unassigned tasks, the
+// creator) of an overdue task. It reports whether the step was recorded,
+// which happens before the notification goes out.
+func (s *EscalationService) notifyAssignee(task *repository.Task) (bool, error) {
+	if _, err := s.history.Create(escalated(task, EscalateAssignee)); err != nil {
+		return false, err
+	}
+
+	recipient := task.AssigneeID
+	if recipient == nil {
+		recipient = task.CreatorID
+	}
+	if recipient == nil {
+		return true, nil
+	}
+
+	return true, s.notifier.Notify(notify.Notification{
+		UserID:  *recipient,
+		TaskID:  &task.ID,
+		Subject: fmt.Sprintf("Task #%d is overdue since %s", task.ID, task.DueDate.Format("2006-01-02")),
+		Body:    task.Title,
+	})
+}
+
+// notifyOwner tells the owner of the task's project about an overdue task
+// and, if configured, raises its priority. Tasks without a project owner go
+// // to the owners of their workspace. It reports whether the step was recorded;
+// when there is no one to notify, it is not. The step and the priority change
+// are stored together before the notifications go out.
+func (s *EscalationService) notifyOwner(ctx context.Context, task *repository.Task) (bool, error) {
+	owners, err := s.owners(task)
+	if err != nil || len(owners) == 0 {
+		return false, err
+	}
+
+	if next, ok := nextPriority[task.Priority]; ok && s.policy.BumpPriority {
+		previous := task.Priority
+		task.Priority = next
+		task.UpdatedAt = time.Now()
+
+		entries := []repository.TaskHistoryEntry{{
+			TaskID:    task.ID,
+			Action:    repository.HistoryPriorityChanged,
+			OldValue:  previous,
+			NewValue:  next,
+			CreatedAt: task.UpdatedAt,
+		}, *escalated(task, EscalateOwner)}
+		if err := s.tasks.UpdateWithHistory(ctx, []repository.Task{*task}, entries); err != nil {
+			return false, err
+		}
+	} else if _, err := s.history.Create(escalated(task, EscalateOwner)); err != nil {
+		return false, err
+	}
+
+	subject := fmt.Sprintf("Task #%d is overdue since %s", task.ID, task.DueDate.Format("2006-01-02"))
+	if task.AssigneeID != nil {
+		subject += fmt.Sprintf(" (assigned to user #%d)", *task.AssigneeID)
+	}
+	var errs []error
+	for _, owner := range owners {
+		err := s.notifier.Notify(notify.Notification{
+			UserID:  owner,
+			TaskID:  &task.ID,
+			Subject: subject,
+			Body:    task.Title,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify user #%d: %w", owner, err))
+		}
+	}
+
+	return true, errors.Join(errs...)
+}
+
+// owners returns the users responsible for a task beyond its assignee: the
+// owner of its project or else the owners of its workspace
+func (s *EscalationService) owners(task *repository.Task) ([]int, error) {
+	if task.ProjectID != nil {
+		project, err := s.projects.FindByID(*task.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if project.OwnerID != nil {
+			return []int{*project.OwnerID}, nil
+		}
+	}
+
+	if task.WorkspaceID == nil {
+		return nil, nil
+	}
+
+	members, err := s.workspaces.FindMembers(*task.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	var owners []int
+	for _, member := range members {
+		if member.Role == policy.RoleOwner {
+			owners = append(owners, member.UserID)
+		}
+	}
+	return owners, nil
+}
+
+// escalated returns the history entry recording an escalation step
+func escalated(task *repository.Task, step string) *repository.TaskHistoryEntry {
+	return &repository.TaskHistoryEntry{
+		TaskID:    task.ID,
+		Action:    repository.HistoryEscalated,
+		OldValue:  formatTime(task.DueDate),
+		NewValue:  step,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// recordingNotifier keeps the notifications it is given and fails for the
+// users listed in failFor
+type recordingNotifier struct {
+	sent    []notify.Notification
+	failFor map[int]bool
+}
+
+func (n *recordingNotifier) Notify(notification notify.Notification) error {
+	if n.failFor[notification.UserID] {
+		return errors.New("inbox unavailable")
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// recipients returns the users notified so far, in ascending order, and
+// forgets the notifications
+func (n *recordingNotifier) recipients() []int {
+	var users []int
+	for _, notification := range n.sent {
+		users = append(users, notification.UserID)
+	}
+	slices.Sort(users)
+	n.sent = nil
+	return users
+}
+
+func TestEscalateOncePerDueDate(t *testing.T) {
+	repos := newEscalationRepos(t)
+	assignee := createEscalationUser(t, repos, "alice")
+	owner := createEscalationUser(t, repos, "olga")
+	project, err := repos.Projects.Create(&repository.Project{Name: "Launch", OwnerID: &owner, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("creating the project failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	task := createOverdueTask(t, repos, &repository.Task{AssigneeID: &assignee, ProjectID: &project.ID}, now.Add(-3*time.Hour))
+
+	notifier := &recordingNotifier{}
+	service := NewEscalationService(repos, notifier, EscalationPolicy{AssigneeAfter: time.Hour, OwnerAfter: 2 * time.Hour})
+
+	taken, err := service.Escalate(context.Background(), now)
+	if err != nil || taken != 2 {
+		t.Fatalf("Escalate = %d, %v; want 2 steps", taken, err)
+	}
+	if got := notifier.recipients(); !slices.Equal(got, []int{assignee, owner}) {
+		t.Errorf("notified %v, want the assignee %d and the project owner %d", got, assignee, owner)
+	}
+
+	taken, err = service.Escalate(context.Background(), now.Add(time.Hour))
+	if err != nil || taken != 0 {
+		t.Fatalf("second Escalate = %d, %v; want no steps", taken, err)
+	}
+	if got := notifier.recipients(); len(got) != 0 {
+		t.Errorf("the second run notified %v again", got)
+	}
+
+	// A new due date starts over
+	due := now.Add(-90 * time.Minute)
+	task.DueDate = &due
+	if _, err := repos.Tasks.Update(context.Background(), task); err != nil {
+		t.Fatalf("moving the due date failed: %v", err)
+	}
+	taken, err = service.Escalate(context.Background(), now)
+	if err != nil || taken != 1 {
+		t.Fatalf("Escalate after a new due date = %d, %v; want 1 step", taken, err)
+	}
+	if got := notifier.recipients(); !slices.Equal(got, []int{assignee}) {
+		t.Errorf("notified %v, want only the assignee %d", got, assignee)
+	}
+}
+
+func TestEscalateFallsBackToWorkspaceOwners(t *testing.T) {
+	repos := newEscalationRepos(t)
+	first := createEscalationUser(t, repos, "olga")
+	second := createEscalationUser(t, repos, "oscar")
+	member := createEscalationUser(t, repos, "mia")
+
+	workspace, err := repos.Workspaces.Create(&repository.Workspace{Name: "Acme", CreatedAt: time.Now()}, first, policy.RoleOwner)
+	if err != nil {
+		t.Fatalf("creating the workspace failed: %v", err)
+	}
+	for user, role := range map[int]string{second: policy.RoleOwner, member: policy.RoleMember} {
+		err := repos.Workspaces.SaveMember(&repository.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user, Role: role, CreatedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("adding a member failed: %v", err)
+		}
+	}
+
+	// A workspace project without an owner falls back as well
+	project, err := repos.Projects.Create(&repository.Project{Name: "Shared", WorkspaceID: &workspace.ID, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("creating the project failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	createOverdueTask(t, repos, &repository.Task{AssigneeID: &member, WorkspaceID: &workspace.ID}, now.Add(-3*time.Hour))
+	createOverdueTask(t, repos, &repository.Task{AssigneeID: &member, WorkspaceID: &workspace.ID, ProjectID: &project.ID}, now.Add(-3*time.Hour))
+
+	notifier := &recordingNotifier{}
+	service := NewEscalationService(repos, notifier, EscalationPolicy{OwnerAfter: time.Hour})
+
+	taken, err := service.Escalate(context.Background(), now)
+	if err != nil || taken != 2 {
+		t.Fatalf("Escalate = %d, %v; want 2 steps", taken, err)
+	}
+	if got := notifier.recipients(); !slices.Equal(got, []int{first, first, second, second}) {
+		t.Errorf("notified %v, want the workspace owners %d and %d for each task", got, first, second)
+	}
+
+	// Personal tasks outside a project have no one to escalate to
+	personal := createOverdueTask(t, repos, &repository.Task{AssigneeID: &member}, now.Add(-3*time.Hour))
+	taken, err = service.Escalate(context.Background(), now)
+	if err != nil || taken != 0 {
+		t.Fatalf("Escalate of a personal task = %d, %v; want no steps", taken, err)
+	}
+	if history, _ := repos.History.FindByTask(personal.ID); len(history) != 0 {
+		t.Errorf("a step without anyone to notify was recorded: %+v", history)
+	}
+}
+
+func TestEscalateBumpsPriority(t *testing.T) {
+	repos := newEscalationRepos(t)
+	owner := createEscalationUser(t, repos, "olga")
+	project, err := repos.Projects.Create(&repository.Project{Name: "Launch", OwnerID: &owner, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("creating the project failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	task := createOverdueTask(t, repos, &repository.Task{ProjectID: &project.ID}, now.Add(-3*time.Hour))
+
+	service := NewEscalationService(repos, &recordingNotifier{}, EscalationPolicy{OwnerAfter: time.Hour, BumpPriority: true})
+	for run := 0; run < 2; run++ {
+		if _, err := service.Escalate(context.Background(), now); err != nil {
+			t.Fatalf("Escalate failed: %v", err)
+		}
+	}
+
+	stored, err := repos.Tasks.FindByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if stored.Priority != PriorityHigh {
+		t.Errorf("priority = %q, want %q after a single bump", stored.Priority, PriorityHigh)
+	}
+
+	history, err := repos.History.FindByTask(task.ID)
+	if err != nil {
+		t.Fatalf("FindByTask failed: %v", err)
+	}
+	changes := 0
+	for _, entry := range history {
+		if entry.Action == repository.HistoryPriorityChanged {
+			changes++
+			if entry.OldValue != PriorityNormal || entry.NewValue != PriorityHigh {
+				t.Errorf("priority change recorded as %q -> %q", entry.OldValue, entry.NewValue)
+			}
+		}
+	}
+	if changes != 1 {
+		t.Errorf("%d priority changes recorded, want 1", changes)
+	}
+}
+
+func TestEscalateContinuesPastFailures(t *testing.T) {
+	repos := newEscalationRepos(t)
+	unreachable := createEscalationUser(t, repos, "alice")
+	reachable := createEscalationUser(t, repos, "bob")
+
+	now := time.Now().UTC()
+	createOverdueTask(t, repos, &repository.Task{AssigneeID: &unreachable}, now.Add(-3*time.Hour))
+	createOverdueTask(t, repos, &repository.Task{AssigneeID: &reachable}, now.Add(-2*time.Hour))
+
+	notifier := &recordingNotifier{failFor: map[int]bool{unreachable: true}}
+	service := NewEscalationService(repos, notifier, EscalationPolicy{AssigneeAfter: time.Hour})
+
+	taken, err := service.Escalate(context.Background(), now)
+	if err == nil {
+		t.Error("Escalate did not report the failed notification")
+	}
+	if taken != 2 {
+		t.Errorf("Escalate took %d steps, want 2", taken)
+	}
+	if got := notifier.recipients(); !slices.Equal(got, []int{reachable}) {
+		t.Errorf("notified %v, want %d despite the failure before", got, reachable)
+	}
+
+	// Steps are recorded before notifying, so nothing is sent twice
+	notifier.failFor = nil
+	taken, err = service.Escalate(context.Background(), now)
+	if err != nil || taken != 0 {
+		t.Fatalf("second Escalate = %d, %v; want no steps", taken, err)
+	}
+	if got := notifier.recipients(); len(got) != 0 {
+		t.Errorf("the second run notified %v", got)
+	}
+}
+
+// newEscalationRepos returns repositories on an empty database
+func newEscalationRepos(t *testing.T) *repository.Repositories {
+	t.Helper()
+
+	db, err := repository.NewDatabase("sqlite3://" + filepath.Join(t.TempDir(), "escalation.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return repository.NewRepositories(db)
+}
+
+func createEscalationUser(t *testing.T, repos *repository.Repositories, username string) int {
+	t.Helper()
+
+	user, err := repos.Users.Create(&repository.User{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "-",
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("creating user %q failed: %v", username, err)
+	}
+	return user.ID
+}
+
+// createOverdueTask stores an open task of normal priority due at due
+func createOverdueTask(t *testing.T, repos *repository.Repositories, task *repository.Task, due time.Time) *repository.Task {
+	t.Helper()
+
+	task.Title = "Overdue"
+	task.Priority = PriorityNormal
+	task.Status = StatusTodo
+	task.DueDate = &due
+	task.CreatedAt = time.Now()
+	task.UpdatedAt = time.Now()
+
+	created, err := repos.Tasks.Create(context.Background(), task)
+	if err != nil {
+		t.Fatalf("creating the task failed: %v", err)
+	}
+	return created
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"time"
+
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// maxJobRuns bounds the number of runs listed per job
+const maxJobRuns = 50
+
+// JobStatus is a scheduled job with the outcome of its latest run
+type JobStatus struct {
+	repository.ScheduledJob
+	LastRun *repository.JobRun `json:"last_run,omitempty"`
+}
+
+// JobService keeps track of scheduled jobs and their runs
+type JobService struct {
+	repo *repository.JobRepository
+}
+
+// NewJobService creates a new JobService
+func NewJobService(repos *repository.Repositories) *JobService {
+	return &JobService{
+		repo: repos.Jobs,
+	}
+}
+
+// List returns the scheduled jobs with their latest run
+func (s *JobService) List() ([]JobStatus, error) {
+	jobs, err := s.repo.FindScheduled()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []JobStatus{}
+	for _, job := range jobs {
+		status := JobStatus{ScheduledJob: job}
+
+		last, err := s.LastRun(job.Name)
+		if err != nil {
+			return nil, err
+		}
+		status.LastRun = last
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Runs returns the recent runs of a job, newest first
+func (s *JobService) Runs(name string) ([]repository.JobRun, error) {
+	if _, err := s.repo.FindScheduledByName(name); err != nil {
+		return nil, err
+	}
+	return s.repo.FindRuns(name, maxJobRuns)
+}
+
+// Trigger asks the schedulers to run a job right away. The leading
+// scheduler instance picks the request up within a few seconds.
+func (s *JobService) Trigger(name string) (*repository.ScheduledJob, error) {
+	if _, err := s.repo.FindScheduledByName(name); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RequestRun(name, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindScheduledByName(name)
+}
+
+// LastRun returns the most recent run of a job, or nil if it never ran
+func (s *JobService) LastRun(name string) (*repository.JobRun, error) {
+	runs, err := s.repo.FindRuns(name, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// Register records a job's schedule and next run time
+func (s *JobService) Register(name, schedule string, next time.Time) error {
+	job := &repository.ScheduledJob{
+		Name:      name,
+		Schedule:  schedule,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if !next.IsZero() {
+		next = next.UTC()
+		job.NextRunAt = &next
+	}
+	return s.repo.SaveScheduled(job)
+}
+
+// ClaimRequested returns the jobs whose manual runs this caller should
+// execute, clearing their requests
+func (s *JobService) ClaimRequested() ([]string, error) {
+	names, err := s.repo.FindRequested()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []string
+	for _, name := range names {
+		ok, err := s.repo.ClaimRequest(name)
+		if err != nil {
+			return claimed, err
+		}
+		if ok {
+			claimed = append(claimed, name)
+		}
+	}
+
+	return claimed, nil
+}
+
+// Start records that an instance started running a job. With a lease, the
+// run is only recorded while the instance still holds it; otherwise Start
+// fails with repository.ErrLeaseLost and the job must not run.
+func (s *JobService) Start(name, trigger, instance string, lease *repository.Lease) (*repository.JobRun, error) {
+	run := &repository.JobRun{
+		Job:       name,
+		Trigger:   trigger,
+		Instance:  instance,
+		Status:    repository.JobRunning,
+		StartedAt: time.Now().UTC(),
+	}
+
+	if lease != nil {
+		return s.repo.CreateRunHolding(run, *lease)
+	}
+	return s.repo.CreateRun(run)
+}
+
+// Finish records the outcome of a job run
+func (s *JobService) Finish(run *repository.JobRun, items int, runErr error) error {
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Items = items
+	run.Status = repository.JobSucceeded
+	if runErr != nil {
+		run.Status = repository.JobFailed
+		run.Error = runErr.Error()
+	}
+	return s.repo.FinishRun(run)
+}
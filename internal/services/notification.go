@@ -0,0 +1,29 @@
+package services
+
+import (
+	"time"
+
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// NotificationService handles a user's notification inbox
+type NotificationService struct {
+	repo *repository.NotificationRepository
+}
+
+// NewNotificationService creates a new NotificationService
+func NewNotificationService(repo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		repo: repo,
+	}
+}
+
+// List returns userID's notifications
+func (s *NotificationService) List(userID int) ([]repository.Notification, error) {
+	return s.repo.FindByUser(userID)
+}
+
+// MarkRead marks one of userID's notifications as read
+func (s *NotificationService) MarkRead(userID, id int) error {
+	return s.repo.MarkRead(id, userID, time.Now())
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// ProjectService handles business logic for projects
+type ProjectService struct {
+	repo   *repository.ProjectRepository
+	policy *policy.Policy
+}
+
+// NewProjectService creates a new ProjectService
+func NewProjectService(repo *repository.ProjectRepository, policy *policy.Policy) *ProjectService {
+	return &ProjectService{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+// Create adds a new project, in a workspace or as a personal project of the actor
+func (s *ProjectService) Create(actor policy.Actor, name string, workspaceID *int) (*repository.Project, error) {
+	if name == "" {
+		return nil, invalid("name is required")
+	}
+	if actor.System {
+		return nil, invalid("projects must be created by a user")
+	}
+
+	if workspaceID != nil {
+		if err := s.policy.Workspace(actor, *workspaceID, policy.CreateProject); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Create(&repository.Project{
+		Name:        name,
+		WorkspaceID: workspaceID,
+		OwnerID:     actor.ID(),
+		CreatedAt:   time.Now(),
+	})
+}
+
+// List returns the projects the actor can see
+func (s *ProjectService) List(actor policy.Actor) ([]repository.Project, error) {
+	return s.repo.FindVisibleTo(actor.UserID)
+}
+
+// Get returns a project
+func (s *ProjectService) Get(actor policy.Actor, id int) (*repository.Project, error) {
+	return s.find(actor, id, policy.ViewTask)
+}
+
+// find loads a project and checks that the actor may perform action in it
+func (s *ProjectService) find(actor policy.Actor, id int, action policy.Action) (*repository.Project, error) {
+	project, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Project(actor, project, action); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Kinds of queued jobs
+const (
+	// QueueNotification delivers a notify.Notification
+	QueueNotification = "notification"
+)
+
+const (
+	// maxQueueJobs bounds the number of jobs listed at once
+	maxQueueJobs = 100
+	// firstRetryDelay is how long a failed job waits before its second
+	// attempt; the delay doubles with every further attempt
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = time.Hour
+)
+
+// QueueOptions configures the background job queue
+type QueueOptions struct {
+	// MaxAttempts is how often a job is tried before it is dead
+	MaxAttempts int
+	// VisibilityTimeout is how long a worker holds a job before another
+	// worker may pick it up again
+	VisibilityTimeout time.Duration
+}
+
+// QueueService enqueues background work and hands it out to workers
+type QueueService struct {
+	repo    *repository.QueueRepository
+	options QueueOptions
+}
+
+// NewQueueService creates a new QueueService
+func NewQueueService(repos *repository.Repositories, options QueueOptions) *QueueService {
+	return &QueueService{
+		repo:    repos.Queue,
+		options: options,
+	}
+}
+
+// Enqueue adds a job of the given kind to the queue. The payload is stored as
+// JSON and handed to the worker that runs the job.
+func (s *QueueService) Enqueue(kind string, payload interface{}) (*repository.QueueJob, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s job: %w", kind, err)
+	}
+
+	now := time.Now().UTC()
+	return s.repo.Create(&repository.QueueJob{
+		Kind:        kind,
+		Payload:     data,
+		Status:      repository.QueueJobQueued,
+		MaxAttempts: max(s.options.MaxAttempts, 1),
+		VisibleAt:   now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// List returns the most recent jobs, optionally only those in a status
+func (s *QueueService) List(status string) ([]repository.QueueJob, error) {
+	switch status {
+	case "", repository.QueueJobQueued, repository.QueueJobRunning, repository.QueueJobDone, repository.QueueJobDead:
+	default:
+		return nil, invalid("status must be queued, running, done or dead")
+	}
+	return s.repo.FindAll(status, maxQueueJobs)
+}
+
+// Retry queues a dead job again with fresh attempts
+func (s *QueueService) Retry(id int) (*repository.QueueJob, error) {
+	retried, err := s.repo.Retry(id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !retried {
+		return nil, conflict("only dead jobs can be retried")
+	}
+
+	return job, nil
+}
+
+// Claim hands the next job to a worker for the visibility timeout. It
+// returns nil when there is nothing to do.
+func (s *QueueService) Claim(worker string) (*repository.QueueJob, error) {
+	now := time.Now().UTC()
+	if _, err := s.repo.Bury(now); err != nil {
+		return nil, err
+	}
+	return s.repo.Claim(worker, now, now.Add(s.options.VisibilityTimeout))
+}
+
+// Complete records that a worker finished a job. It reports false when the
+// worker no longer held the job.
+func (s *QueueService) Complete(job *repository.QueueJob) (bool, error) {
+	now := time.Now().UTC()
+	job.Status = repository.QueueJobDone
+	job.LastError = ""
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+	return s.repo.Finish(job)
+}
+
+// Fail records a failed attempt. The job is retried with an exponential
+// backoff until it runs out of attempts and is dead.
+func (s *QueueService) Fail(job *repository.QueueJob, jobErr error) (bool, error) {
+	now := time.Now().UTC()
+	job.LastError = jobErr.Error()
+	job.UpdatedAt = now
+
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = repository.QueueJobDead
+		job.FinishedAt = &now
+	} else {
+		job.Status = repository.QueueJobQueued
+		job.VisibleAt = now.Add(retryDelay(job.Attempts))
+	}
+
+	return s.repo.Finish(job)
+}
+
+// retryDelay returns how long to wait after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuickAddResult is the interpretation of a quick-add line
+type QuickAddResult struct {
+	Title      string     `json:"title"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	Tags       []string   `json:"tags"`
+	Priority   string     `json:"priority"`
+	Assignee   string     `json:"assignee,omitempty"`
+	AssigneeID *int       `json:"assignee_id,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+}
+
+var (
+	isoDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var rruleDays = map[time.Weekday]string{
+	time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+	time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+}
+
+// ParseQuickAdd interprets a single line such as
+// "Send report tomorrow 5pm #finance !high @alice every friday".
+// Relative dates are resolved against now.
+func ParseQuickAdd(input string, now time.Time) (*QuickAddResult, error) {
+	p := &quickAddParser{
+		words: strings.Fields(input),
+		now:   now,
+		result: &QuickAddResult{
+			Tags:     []string{},
+			Priority: PriorityNormal,
+		},
+	}
+	return p.parse()
+}
+
+type quickAddParser struct {
+	words  []string
+	pos    int
+	now    time.Time
+	result *QuickAddResult
+
+	date     *time.Time
+	clock    *clockTime
+	firstDay *time.Weekday
+	title    []string
+	// inHours is set by "in N hours", which is a complete due date
+	inHours *time.Time
+}
+
+// clockTime is a time of day
+type clockTime struct {
+	hour, minute int
+}
+
+// on returns the time of day on the date of day, in its location
+func (c clockTime) on(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, 0, 0, day.Location())
+}
+
+func (p *quickAddParser) parse() (*QuickAddResult, error) {
+	for p.pos < len(p.words) {
+		word := p.words[p.pos]
+		lower := strings.ToLower(word)
+
+		switch {
+		case len(word) > 1 && word[0] == '#':
+			tag := strings.ToLower(word[1:])
+			if err := checkTags([]string{tag}); err != nil {
+				return nil, err
+			}
+			p.result.Tags = append(p.result.Tags, tag)
+			p.pos++
+		case len(word) > 1 && word[0] == '!':
+			priority, err := parsePriority(lower[1:])
+			if err != nil {
+				return nil, err
+			}
+			p.result.Priority = priority
+			p.pos++
+		case len(word) > 1 && word[0] == '@':
+			p.result.Assignee = word[1:]
+			p.pos++
+		case lower == "every" || lower == "daily" || lower == "weekly" || lower == "monthly" || lower == "yearly":
+			if !p.parseRecurrence() {
+				p.title = append(p.title, word)
+				p.pos++
+			}
+		case (lower == "on" || lower == "at" || lower == "by" || lower == "due") && p.pos+1 < len(p.words):
+			// Connectors are dropped only when a date or time follows
+			p.pos++
+			if !p.parseDateOrTime() {
+				p.title = append(p.title, word)
+			}
+		default:
+			if !p.parseDateOrTime() {
+				p.title = append(p.title, word)
+				p.pos++
+			}
+		}
+	}
+
+	p.result.Title = strings.Join(p.title, " ")
+	if p.result.Title == "" {
+		return nil, invalid("quick-add text must contain a title")
+	}
+
+	if err := p.resolveDueDate(); err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
+
+// parseDateOrTime consumes a date or time phrase at the current position
+func (p *quickAddParser) parseDateOrTime() bool {
+	lower := strings.ToLower(p.words[p.pos])
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+
+	switch {
+	case lower == "today" || lower == "tonight":
+		p.setDate(today)
+		if lower == "tonight" && p.clock == nil {
+			p.clock = &clockTime{hour: 20}
+		}
+		p.pos++
+		return true
+	case lower == "tomorrow" || lower == "tmr":
+		p.setDate(today.AddDate(0, 0, 1))
+		p.pos++
+		return true
+	case lower == "noon" || lower == "midnight":
+		clock := clockTime{hour: 12}
+		if lower == "midnight" {
+			clock.hour = 0
+		}
+		p.clock = &clock
+		p.pos++
+		return true
+	case isoDatePattern.MatchString(lower):
+		date, err := time.ParseInLocation("2006-01-02", lower, p.now.Location())
+		if err != nil {
+			return false
+		}
+		p.setDate(date)
+		p.pos++
+		return true
+	case lower == "next" && p.pos+1 < len(p.words):
+		next := strings.ToLower(p.words[p.pos+1])
+		if next == "week" {
+			p.setDate(nextWeekday(today.AddDate(0, 0, 1), time.Monday))
+			p.pos += 2
+			return true
+		}
+		if day, ok := weekdays[next]; ok {
+			p.setDate(nextWeekday(today.AddDate(0, 0, 1), day))
+			p.pos += 2
+			return true
+		}
+	case lower == "in" && p.pos+2 < len(p.words):
+		n, err := strconv.Atoi(p.words[p.pos+1])
+		if err != nil || n <= 0 {
+			return false
+		}
+		switch strings.TrimSuffix(strings.ToLower(p.words[p.pos+2]), "s") {
+		case "day":
+			p.setDate(today.AddDate(0, 0, n))
+		case "week":
+			p.setDate(today.AddDate(0, 0, 7*n))
+		case "month":
+			p.setDate(today.AddDate(0, n, 0))
+		case "hour":
+			due := p.now.Add(time.Duration(n) * time.Hour).Truncate(time.Minute)
+			p.inHours = &due
+		default:
+			return false
+		}
+		p.pos += 3
+		return true
+	}
+
+	if day, ok := weekdays[lower]; ok {
+		// A weekday name always refers to an upcoming day, never today
+		p.setDate(nextWeekday(today.AddDate(0, 0, 1), day))
+		p.pos++
+		return true
+	}
+
+	if clock, ok := parseClock(lower); ok {
+		p.clock = &clock
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// parseRecurrence consumes phrases like "every friday", "every 2 weeks", "daily"
+func (p *quickAddParser) parseRecurrence() bool {
+	lower := strings.ToLower(p.words[p.pos])
+
+	switch lower {
+	case "daily":
+		p.result.Recurrence = "FREQ=DAILY"
+		p.pos++
+		return true
+	case "weekly":
+		p.result.Recurrence = "FREQ=WEEKLY"
+		p.pos++
+		return true
+	case "monthly":
+		p.result.Recurrence = "FREQ=MONTHLY"
+		p.pos++
+		return true
+	case "yearly":
+		p.result.Recurrence = "FREQ=YEARLY"
+		p.pos++
+		return true
+	}
+
+	// "every ..."
+	if p.pos+1 >= len(p.words) {
+		return false
+	}
+	next := strings.ToLower(p.words[p.pos+1])
+	consumed := 2
+	interval := 1
+	if n, err := strconv.Atoi(next); err == nil && n > 0 && p.pos+2 < len(p.words) {
+		interval = n
+		next = strings.ToLower(p.words[p.pos+2])
+		consumed = 3
+	}
+
+	var rule string
+	switch strings.TrimSuffix(next, "s") {
+	case "day":
+		rule = "FREQ=DAILY"
+	case "weekday":
+		rule = "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+	case "week":
+		rule = "FREQ=WEEKLY"
+	case "month":
+		rule = "FREQ=MONTHLY"
+	case "year":
+		rule = "FREQ=YEARLY"
+	default:
+		day, ok := weekdays[next]
+		if !ok || interval != 1 {
+			return false
+		}
+		rule = "FREQ=WEEKLY;BYDAY=" + rruleDays[day]
+		p.firstDay = &day
+	}
+
+	if interval > 1 {
+		rule += fmt.Sprintf(";INTERVAL=%d", interval)
+	}
+	p.result.Recurrence = rule
+	p.pos += consumed
+	return true
+}
+
+func (p *quickAddParser) setDate(date time.Time) {
+	p.date = &date
+}
+
+// resolveDueDate combines the parsed date, time and recurrence into a due date
+func (p *quickAddParser) resolveDueDate() error {
+	if p.inHours != nil {
+		if p.date != nil || p.clock != nil {
+			return invalid(`"in N hours" cannot be combined with another date or time`)
+		}
+		p.result.DueDate = p.inHours
+		return nil
+	}
+
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+	date := p.date
+	if date == nil && p.firstDay != nil {
+		// "every friday" without a date starts on the first matching day
+		first := nextWeekday(today, *p.firstDay)
+		date = &first
+	}
+
+	if date == nil && p.clock == nil {
+		return nil
+	}
+
+	if date == nil {
+		// A bare time means the next occurrence of that time
+		due := p.clock.on(today)
+		if !due.After(p.now) {
+			due = p.clock.on(today.AddDate(0, 0, 1))
+		}
+		p.result.DueDate = &due
+		return nil
+	}
+
+	due := *date
+	if p.clock != nil {
+		due = p.clock.on(due)
+	}
+	p.result.DueDate = &due
+	return nil
+}
+
+// parseClock parses "5pm", "5:30pm" and "17:00" into a time of day
+func parseClock(s string) (clockTime, bool) {
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		// A bare number is not a time
+		return clockTime{}, false
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return clockTime{}, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return clockTime{}, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return clockTime{}, false
+	}
+	return clockTime{hour: hour, minute: minute}, true
+}
+
+func parsePriority(s string) (string, error) {
+	switch s {
+	case "low", "l":
+		return PriorityLow, nil
+	case "normal", "medium", "m":
+		return PriorityNormal, nil
+	case "high", "h":
+		return PriorityHigh, nil
+	case "urgent", "u":
+		return PriorityUrgent, nil
+	}
+	return "", invalid(fmt.Sprintf("unknown priority %q", s))
+}
+
+// nextWeekday returns the first date on or after from that falls on day
+func nextWeekday(from time.Time, day time.Weekday) time.Time {
+	diff := (int(day) - int(from.Weekday()) + 7) % 7
+	return from.AddDate(0, 0, diff)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+	at := func(year int, month time.Month, day, hour, minute int) *time.Time {
+		t := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		input string
+		want  QuickAddResult
+	}{
+		{"Buy milk", QuickAddResult{Title: "Buy milk"}},
+		{"Pay rent today", QuickAddResult{Title: "Pay rent", DueDate: at(2024, 3, 6, 0, 0)}},
+		{"Call mum tonight", QuickAddResult{Title: "Call mum", DueDate: at(2024, 3, 6, 20, 0)}},
+		{"Send report tomorrow 5pm", QuickAddResult{Title: "Send report", DueDate: at(2024, 3, 7, 17, 0)}},
+		{"Send report tmr at 17:30", QuickAddResult{Title: "Send report", DueDate: at(2024, 3, 7, 17, 30)}},
+		{"Lunch noon", QuickAddResult{Title: "Lunch", DueDate: at(2024, 3, 6, 12, 0)}},
+		{"Standup 9am", QuickAddResult{Title: "Standup", DueDate: at(2024, 3, 7, 9, 0)}},
+		{"Release on 2024-04-01", QuickAddResult{Title: "Release", DueDate: at(2024, 4, 1, 0, 0)}},
+		{"Review friday", QuickAddResult{Title: "Review", DueDate: at(2024, 3, 8, 0, 0)}},
+		{"Review wed", QuickAddResult{Title: "Review", DueDate: at(2024, 3, 13, 0, 0)}},
+		{"Plan next week", QuickAddResult{Title: "Plan", DueDate: at(2024, 3, 11, 0, 0)}},
+		{"Plan next monday 8:15am", QuickAddResult{Title: "Plan", DueDate: at(2024, 3, 11, 8, 15)}},
+		{"Renew in 3 days", QuickAddResult{Title: "Renew", DueDate: at(2024, 3, 9, 0, 0)}},
+		{"Renew in 2 weeks", QuickAddResult{Title: "Renew", DueDate: at(2024, 3, 20, 0, 0)}},
+		{"Renew in 1 month", QuickAddResult{Title: "Renew", DueDate: at(2024, 4, 6, 0, 0)}},
+		{"Check oven in 2 hours", QuickAddResult{Title: "Check oven", DueDate: at(2024, 3, 6, 12, 0)}},
+		{"Water plants daily", QuickAddResult{Title: "Water plants", Recurrence: "FREQ=DAILY"}},
+		{"Backup every 2 weeks", QuickAddResult{Title: "Backup", Recurrence: "FREQ=WEEKLY;INTERVAL=2"}},
+		{"Timesheet every weekday 5pm", QuickAddResult{Title: "Timesheet", DueDate: at(2024, 3, 6, 17, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}},
+		{"Retro every friday", QuickAddResult{Title: "Retro", DueDate: at(2024, 3, 8, 0, 0), Recurrence: "FREQ=WEEKLY;BYDAY=FR"}},
+		{"Retro every wednesday 3pm", QuickAddResult{Title: "Retro", DueDate: at(2024, 3, 6, 15, 0), Recurrence: "FREQ=WEEKLY;BYDAY=WE"}},
+		{"Fix login #Bug #backend", QuickAddResult{Title: "Fix login", Tags: []string{"bug", "backend"}}},
+		{"Deploy !urgent", QuickAddResult{Title: "Deploy", Priority: PriorityUrgent}},
+		{"Deploy !l", QuickAddResult{Title: "Deploy", Priority: PriorityLow}},
+		{"Review PR @alice", QuickAddResult{Title: "Review PR", Assignee: "alice"}},
+		{"Meet at the office", QuickAddResult{Title: "Meet at the office"}},
+		{"Read chapter 12", QuickAddResult{Title: "Read chapter 12"}},
+		{"Every so often", QuickAddResult{Title: "Every so often"}},
+		{
+			"Send report tomorrow 5pm #finance !high @alice every friday",
+			QuickAddResult{Title: "Send report", DueDate: at(2024, 3, 7, 17, 0), Tags: []string{"finance"}, Priority: PriorityHigh, Assignee: "alice", Recurrence: "FREQ=WEEKLY;BYDAY=FR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if tt.want.Tags == nil {
+				tt.want.Tags = []string{}
+			}
+			if tt.want.Priority == "" {
+				tt.want.Priority = PriorityNormal
+			}
+
+			got, err := ParseQuickAdd(tt.input, now)
+			if err != nil {
+				t.Fatalf("ParseQuickAdd(%q) failed: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseQuickAdd(%q)\n got %+v\nwant %+v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddRejects(t *testing.T) {
+	now := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+
+	tests := []string{
+		"",
+		"tomorrow 5pm #tag",
+		"Deploy !someday",
+		"Tag it #a,b",
+		"Check oven in 2 hours tomorrow",
+		"Check oven 5pm in 2 hours",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseQuickAdd(input, now)
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				t.Errorf("ParseQuickAdd(%q) = %v, want a validation error", input, err)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddDaylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	// Clocks go forward at 2am on 2024-03-10, so that day has 23 hours
+	now := time.Date(2024, 3, 10, 0, 30, 0, 0, location)
+
+	for input, want := range map[string]time.Time{
+		"Dinner today 5pm":       time.Date(2024, 3, 10, 17, 0, 0, 0, location),
+		"Dinner 6pm":             time.Date(2024, 3, 10, 18, 0, 0, 0, location),
+		"Breakfast tomorrow 8am": time.Date(2024, 3, 11, 8, 0, 0, 0, location),
+	} {
+		got, err := ParseQuickAdd(input, now)
+		if err != nil {
+			t.Fatalf("ParseQuickAdd(%q) failed: %v", input, err)
+		}
+		if got.DueDate == nil || !got.DueDate.Equal(want) {
+			t.Errorf("ParseQuickAdd(%q) due %v, want %v", input, got.DueDate, want)
+		}
+	}
+}
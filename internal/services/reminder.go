@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// ReminderInput holds the user supplied fields of a new reminder. Exactly one
+// of At (RFC 3339) and BeforeDue (an offset like "1h", "2d" or "1w") is set.
+type ReminderInput struct {
+	At        string
+	BeforeDue string
+}
+
+// ReminderService handles business logic for task reminders
+type ReminderService struct {
+	repo     *repository.ReminderRepository
+	tasks    *repository.TaskRepository
+	policy   *policy.Policy
+	notifier notify.Notifier
+}
+
+// NewReminderService creates a new ReminderService
+func NewReminderService(repos *repository.Repositories, policy *policy.Policy, notifier notify.Notifier) *ReminderService {
+	return &ReminderService{
+		repo:     repos.Reminders,
+		tasks:    repos.Tasks,
+		policy:   policy,
+		notifier: notifier,
+	}
+}
+
+// List returns the actor's reminders on a task
+func (s *ReminderService) List(ctx context.Context, actor policy.Actor, taskID int) ([]repository.Reminder, error) {
+	if _, err := s.find(ctx, actor, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByTask(ctx, taskID, actor.UserID)
+}
+
+// Create schedules a reminder about a task for the actor
+func (s *ReminderService) Create(ctx context.Context, actor policy.Actor, taskID int, input ReminderInput) (*repository.Reminder, error) {
+	task, err := s.find(ctx, actor, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (input.At == "") == (input.BeforeDue == "") {
+		return nil, invalid("either at or before_due is required")
+	}
+
+	now := time.Now().UTC()
+	reminder := &repository.Reminder{
+		TaskID:    taskID,
+		UserID:    actor.UserID,
+		BeforeDue: input.BeforeDue,
+		Status:    repository.ReminderPending,
+		CreatedAt: now,
+	}
+
+	if input.At != "" {
+		at, err := time.Parse(time.RFC3339, input.At)
+		if err != nil {
+			return nil, invalid("invalid at, expected an RFC 3339 timestamp")
+		}
+		at = at.UTC()
+		reminder.RemindAt = &at
+	} else {
+		if !dueOffsetPattern.MatchString(input.BeforeDue) || parseDueOffset(input.BeforeDue) <= 0 {
+			return nil, invalid(fmt.Sprintf("invalid before_due %q, expected a number of hours, days or weeks like 1h, 2d or 1w", input.BeforeDue))
+		}
+		reminder.RemindAt = remindAt(task.DueDate, input.BeforeDue)
+	}
+
+	if reminder.RemindAt != nil && !reminder.RemindAt.After(now) {
+		return nil, invalid("the reminder time has already passed")
+	}
+
+	return s.repo.Create(reminder)
+}
+
+// Delete cancels one of the actor's reminders
+func (s *ReminderService) Delete(ctx context.Context, actor policy.Actor, taskID, id int) error {
+	if _, err := s.find(ctx, actor, taskID); err != nil {
+		return err
+	}
+
+	reminder, err := s.repo.FindByID(taskID, id)
+	if err != nil {
+		return err
+	}
+	if reminder.UserID != actor.UserID {
+		return repository.ErrReminderNotFound
+	}
+
+	return s.repo.Delete(id)
+}
+
+// NextAt returns the time of the next pending reminder, or nil when none is scheduled
+func (s *ReminderService) NextAt(ctx context.Context) (*time.Time, error) {
+	return s.repo.NextAt(ctx)
+}
+
+// Dispatch delivers the reminders that are due by now and returns how many
+// were sent. Each reminder is claimed before delivery, so that it is never
+// sent twice, even by concurrent dispatchers; a dispatcher that stops between
+// claiming and recording the outcome leaves the reminder in the sending state.
+// When ctx is cancelled it stops before claiming the next reminder.
+func (s *ReminderService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	reminders, err := s.repo.FindDue(ctx, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		claimed, err := s.repo.Claim(ctx, reminder.ID)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		// A claimed reminder is delivered and its outcome recorded even if
+		// ctx is cancelled meanwhile
+		claimedCtx := context.WithoutCancel(ctx)
+		status, message := s.deliver(claimedCtx, reminder)
+
+		var sentAt *time.Time
+		if status == repository.ReminderSent {
+			delivered := time.Now().UTC()
+			sentAt = &delivered
+			sent++
+		}
+		if err := s.repo.Finish(claimedCtx, reminder.ID, status, message, sentAt); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver notifies the recipient of a reminder and returns the resulting
+// status with an explanation for reminders that were not sent
+func (s *ReminderService) deliver(ctx context.Context, reminder repository.Reminder) (string, string) {
+	task, err := s.tasks.FindByID(ctx, reminder.TaskID)
+	if err != nil {
+		return repository.ReminderFailed, err.Error()
+	}
+
+	if task.Completed {
+		return repository.ReminderSkipped, "the task is completed"
+	}
+
+	// The recipient may have lost access since the reminder was created
+	if err := s.policy.Task(policy.User(reminder.UserID), task, policy.ViewTask); err != nil {
+		return repository.ReminderSkipped, "the recipient can no longer see the task"
+	}
+
+	subject := fmt.Sprintf("Reminder: task #%d", task.ID)
+	if task.DueDate != nil {
+		subject = fmt.Sprintf("Reminder: task #%d is due %s", task.ID, task.DueDate.Format("2006-01-02"))
+	}
+
+	err = s.notifier.Notify(notify.Notification{
+		UserID:  reminder.UserID,
+		TaskID:  &task.ID,
+		Subject: subject,
+		Body:    task.Title,
+	})
+	if err != nil {
+		return repository.ReminderFailed, err.Error()
+	}
+
+	return repository.ReminderSent, ""
+}
+
+// find loads a task the actor may see. Reminders belong to users, so the
+// system actor cannot have any.
+func (s *ReminderService) find(ctx context.Context, actor policy.Actor, taskID int) (*repository.Task, error) {
+	if actor.System {
+		return nil, invalid("reminders belong to a user")
+	}
+
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Task(actor, task, policy.ViewTask); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// remindAt returns the time of a reminder relative to a due date, or nil
+// without a due date
+func remindAt(due *time.Time, beforeDue string) *time.Time {
+	if due == nil {
+		return nil
+	}
+	at := due.UTC().Add(-parseDueOffset(beforeDue))
+	return &at
+}
@@ -1,101 +1,1023 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"golang_task_manager_folder_structure/internal/logger"
+	"golang_task_manager_folder_structure/internal/notify"
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/rank"
 	"golang_task_manager_folder_structure/internal/repository"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("golang_task_manager_folder_structure/internal/services")
+
+// Task priorities, from lowest to highest
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// Default task statuses. Boards may map columns to other statuses as well;
+// a task is completed exactly when its status is StatusDone.
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
 )
 
+// TaskInput holds the user supplied fields of a new task
+type TaskInput struct {
+	Title       string
+	Description string
+	DueDate     string
+	WorkspaceID *int
+	AssigneeID  *int
+	ProjectID   *int
+	// ParentID makes the task a subtask; it inherits the parent's workspace and project
+	ParentID *int
+	// EstimateMinutes is the planned effort, nil when not estimated
+	EstimateMinutes *int
+}
+
+// TaskUpdate holds the fields of a task update. Empty strings and nil
+// values leave the field unchanged; an estimate of 0 clears it.
+type TaskUpdate struct {
+	Title           string
+	Description     string
+	DueDate         string
+	EstimateMinutes *int
+}
+
+// DuplicateOptions selects what is copied along with a task. Duplicates
+// always start out unassigned and not completed.
+type DuplicateOptions struct {
+	// Title replaces the title of the copy, which defaults to "<title> (copy)"
+	Title       string
+	Subtasks    bool
+	Checklist   bool
+	Tags        bool
+	Attachments bool
+	Comments    bool
+}
+
+// TaskOptions holds the configurable rules of the TaskService
+type TaskOptions struct {
+	// RequireChecklistComplete refuses to complete tasks with unchecked checklist items
+	RequireChecklistComplete bool
+}
+
 // TaskService handles business logic for tasks
 type TaskService struct {
-	repo *repository.TaskRepository
+	repo        *repository.TaskRepository
+	users       *repository.UserRepository
+	workspaces  *repository.WorkspaceRepository
+	projects    *repository.ProjectRepository
+	history     *repository.HistoryRepository
+	checklists  *repository.ChecklistRepository
+	comments    *repository.CommentRepository
+	reminders   *repository.ReminderRepository
+	policy      *policy.Policy
+	queue       *QueueService
+	attachments *AttachmentService
+	options     TaskOptions
+	logger      *logger.Logger
 }
 
 // NewTaskService creates a new TaskService
-func NewTaskService(repo *repository.TaskRepository) *TaskService {
+func NewTaskService(repos *repository.Repositories, policy *policy.Policy, queue *QueueService, attachments *AttachmentService, options TaskOptions, logger *logger.Logger) *TaskService {
 	return &TaskService{
-		repo: repo,
+		repo:        repos.Tasks,
+		users:       repos.Users,
+		workspaces:  repos.Workspaces,
+		projects:    repos.Projects,
+		history:     repos.History,
+		checklists:  repos.Checklists,
+		comments:    repos.Comments,
+		reminders:   repos.Reminders,
+		policy:      policy,
+		queue:       queue,
+		attachments: attachments,
+		options:     options,
+		logger:      logger,
 	}
 }
 
-// GetAll returns all tasks
-func (s *TaskService) GetAll() ([]repository.Task, error) {
-	return s.repo.FindAll()
+// GetAll returns the tasks matching the filter that the actor may see
+func (s *TaskService) GetAll(ctx context.Context, actor policy.Actor, filter repository.TaskFilter) ([]repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.GetAll")
+	defer span.End()
+
+	if !actor.System {
+		filter.VisibleTo = &actor.UserID
+	}
+	return s.repo.FindAll(ctx, filter)
 }
 
 // GetByID returns a task by ID
-func (s *TaskService) GetByID(id int) (*repository.Task, error) {
-	return s.repo.FindByID(id)
+func (s *TaskService) GetByID(ctx context.Context, actor policy.Actor, id int) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.GetByID")
+	defer span.End()
+
+	return s.find(ctx, actor, id, policy.ViewTask)
+}
+
+// find loads a task and checks that the actor may perform action on it
+func (s *TaskService) find(ctx context.Context, actor policy.Actor, id int, action policy.Action) (*repository.Task, error) {
+	task, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Task(actor, task, action); err != nil {
+		return nil, err
+	}
+
+	return task, nil
 }
 
-// Create adds a new task
-func (s *TaskService) Create(title, description, dueDate string) (*repository.Task, error) {
+// Create adds a new task on behalf of the actor
+func (s *TaskService) Create(ctx context.Context, actor policy.Actor, input TaskInput) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Create")
+	defer span.End()
+
 	var due *time.Time
 
-	if dueDate != "" {
-		parsedDate, err := time.Parse("2006-01-02", dueDate)
+	if input.DueDate != "" {
+		parsedDate, err := time.Parse("2006-01-02", input.DueDate)
 		if err != nil {
-			return nil, errors.New("invalid due date format, expected YYYY-MM-DD")
+			return nil, invalid("invalid due date format, expected YYYY-MM-DD")
 		}
 		due = &parsedDate
 	}
 
+	if err := checkEstimate(input.EstimateMinutes); err != nil {
+		return nil, err
+	}
+
 	task := &repository.Task{
-		Title:       title,
-		Description: description,
-		DueDate:     due,
-		Completed:   false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Title:           input.Title,
+		Description:     input.Description,
+		DueDate:         due,
+		Priority:        PriorityNormal,
+		CreatorID:       actor.ID(),
+		WorkspaceID:     input.WorkspaceID,
+		ProjectID:       input.ProjectID,
+		ParentID:        input.ParentID,
+		EstimateMinutes: input.EstimateMinutes,
+		Completed:       false,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if task.ParentID != nil {
+		if err := s.placeUnderParent(ctx, actor, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if task.ProjectID != nil {
+		if err := s.placeInProject(actor, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if task.WorkspaceID != nil {
+		if err := s.policy.Workspace(actor, *task.WorkspaceID, policy.CreateTask); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.create(ctx, actor, task, input.AssigneeID)
+}
+
+// placeInProject checks that the actor may add tasks to the task's project
+// and moves the task into the project's workspace
+func (s *TaskService) placeInProject(actor policy.Actor, task *repository.Task) error {
+	project, err := s.projects.FindByID(*task.ProjectID)
+	if err == repository.ErrProjectNotFound {
+		return invalid("unknown project")
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.policy.Project(actor, project, policy.CreateTask); err != nil {
+		return err
+	}
+
+	if task.WorkspaceID != nil && !sameUser(task.WorkspaceID, project.WorkspaceID) {
+		return invalid("the project does not belong to the task's workspace")
+	}
+	task.WorkspaceID = project.WorkspaceID
+
+	return nil
+}
+
+// placeUnderParent checks the parent of a new subtask, which shares the
+// parent's workspace and project
+func (s *TaskService) placeUnderParent(ctx context.Context, actor policy.Actor, task *repository.Task) error {
+	parent, err := s.find(ctx, actor, *task.ParentID, policy.ViewTask)
+	if err == repository.ErrTaskNotFound {
+		return invalid("unknown parent task")
+	} else if err != nil {
+		return err
+	}
+
+	if task.WorkspaceID != nil && !sameUser(task.WorkspaceID, parent.WorkspaceID) {
+		return invalid("a subtask must be in the workspace of its parent")
+	}
+	if task.ProjectID != nil && !sameUser(task.ProjectID, parent.ProjectID) {
+		return invalid("a subtask must be in the project of its parent")
+	}
+	task.WorkspaceID = parent.WorkspaceID
+	task.ProjectID = parent.ProjectID
+
+	return nil
+}
+
+// create stores a task at the end of the manual order together with its
+// initial assignment, which is checked before anything is stored
+func (s *TaskService) create(ctx context.Context, actor policy.Actor, task *repository.Task, assigneeID *int) (*repository.Task, error) {
+	var entries []repository.TaskHistoryEntry
+	if assigneeID != nil {
+		if err := s.policy.Task(actor, task, policy.AssignTask); err != nil {
+			return nil, err
+		}
+		if err := s.checkAssignee(task, *assigneeID); err != nil {
+			return nil, err
+		}
+
+		task.AssigneeID = assigneeID
+		entries = append(entries, repository.TaskHistoryEntry{
+			ActorID:   actor.ID(),
+			Action:    repository.HistoryAssigned,
+			NewValue:  formatUserID(assigneeID),
+			CreatedAt: task.CreatedAt,
+		})
+	}
+
+	if task.Status == "" {
+		task.Status = StatusTodo
+	}
+
+	last, err := s.repo.LastRank(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task.Rank, _ = rank.Between(last, "")
+
+	created, err := s.repo.CreateWithHistory(ctx, task, entries)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.Create(task)
+	if assigneeID != nil {
+		s.announceAssignment(ctx, actor, created)
+	}
+
+	return created, nil
+}
+
+// QuickAdd parses a single line of text into a task created by the actor.
+// When dryRun is set the parsed interpretation is returned without creating
+// the task.
+func (s *TaskService) QuickAdd(ctx context.Context, actor policy.Actor, text string, dryRun bool) (*QuickAddResult, *repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.QuickAdd")
+	defer span.End()
+
+	parsed, err := ParseQuickAdd(text, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parsed.Assignee != "" {
+		user, err := s.users.FindByUsername(parsed.Assignee)
+		if err == nil {
+			parsed.AssigneeID = &user.ID
+		} else if err != repository.ErrUserNotFound {
+			return nil, nil, err
+		} else if !dryRun {
+			return nil, nil, invalid("unknown assignee @" + parsed.Assignee)
+		}
+	}
+
+	if dryRun {
+		return parsed, nil, nil
+	}
+
+	task := &repository.Task{
+		Title:      parsed.Title,
+		DueDate:    parsed.DueDate,
+		Priority:   parsed.Priority,
+		Tags:       parsed.Tags,
+		Recurrence: parsed.Recurrence,
+		CreatorID:  actor.ID(),
+		Completed:  false,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	created, err := s.create(ctx, actor, task, parsed.AssigneeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parsed, created, nil
 }
 
 // Update modifies an existing task
-func (s *TaskService) Update(id int, title, description, dueDate string) (*repository.Task, error) {
-	task, err := s.repo.FindByID(id)
+func (s *TaskService) Update(ctx context.Context, actor policy.Actor, id int, update TaskUpdate) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Update")
+	defer span.End()
+
+	task, err := s.find(ctx, actor, id, policy.EditTask)
 	if err != nil {
 		return nil, err
 	}
 
-	if title != "" {
-		task.Title = title
+	if update.Title != "" {
+		task.Title = update.Title
 	}
 
-	if description != "" {
-		task.Description = description
+	if update.Description != "" {
+		task.Description = update.Description
 	}
 
-	if dueDate != "" {
-		parsedDate, err := time.Parse("2006-01-02", dueDate)
+	if update.DueDate != "" {
+		parsedDate, err := time.Parse("2006-01-02", update.DueDate)
 		if err != nil {
-			return nil, errors.New("invalid due date format, expected YYYY-MM-DD")
+			return nil, invalid("invalid due date format, expected YYYY-MM-DD")
 		}
 		task.DueDate = &parsedDate
 	}
 
+	if update.EstimateMinutes != nil {
+		if err := checkEstimate(update.EstimateMinutes); err != nil {
+			return nil, err
+		}
+		task.EstimateMinutes = update.EstimateMinutes
+		if *update.EstimateMinutes == 0 {
+			task.EstimateMinutes = nil
+		}
+	}
+
 	task.UpdatedAt = time.Now()
 
-	return s.repo.Update(task)
+	if _, err := s.repo.Update(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if update.DueDate != "" {
+		if err := s.rescheduleReminders(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+
+	return task, nil
 }
 
-// Delete removes a task
-func (s *TaskService) Delete(id int) error {
-	return s.repo.Delete(id)
+// rescheduleReminders moves the pending reminders that are relative to the
+// due date of a task along with it
+func (s *TaskService) rescheduleReminders(ctx context.Context, task *repository.Task) error {
+	reminders, err := s.reminders.FindPendingRelative(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		if err := s.reminders.Reschedule(ctx, reminder.ID, remindAt(task.DueDate, reminder.BeforeDue)); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// Complete marks a task as completed
-func (s *TaskService) Complete(id int) (*repository.Task, error) {
-	task, err := s.repo.FindByID(id)
+// Assign sets or clears (nil assigneeID) the assignee of a task, recording
+// the change in the task history and notifying the new assignee
+func (s *TaskService) Assign(ctx context.Context, actor policy.Actor, id int, assigneeID *int) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Assign")
+	defer span.End()
+
+	task, err := s.find(ctx, actor, id, policy.AssignTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if assigneeID != nil {
+		if err := s.checkAssignee(task, *assigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := task.AssigneeID
+	if sameUser(previous, assigneeID) {
+		return task, nil
+	}
+
+	task.AssigneeID = assigneeID
+	task.UpdatedAt = time.Now()
+	if _, err := s.repo.Update(ctx, task); err != nil {
+		return nil, err
+	}
+
+	_, err = s.history.Create(&repository.TaskHistoryEntry{
+		TaskID:    task.ID,
+		ActorID:   actor.ID(),
+		Action:    repository.HistoryAssigned,
+		OldValue:  formatUserID(previous),
+		NewValue:  formatUserID(assigneeID),
+		CreatedAt: time.Now(),
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if assigneeID != nil {
+		s.announceAssignment(ctx, actor, task)
+	}
+
+	return task, nil
+}
+
+// announceAssignment queues a notification to the assignee of a task unless
+// they assigned themselves. Notifications are delivered by the queue
+// workers; the assignment stands even if the notification cannot be queued,
+// so that clients do not retry (and duplicate) the change.
+func (s *TaskService) announceAssignment(ctx context.Context, actor policy.Actor, task *repository.Task) {
+	if task.AssigneeID == nil || (!actor.System && *task.AssigneeID == actor.UserID) {
+		return
+	}
+
+	_, err := s.queue.Enqueue(QueueNotification, notify.Notification{
+		UserID:  *task.AssigneeID,
+		TaskID:  &task.ID,
+		Subject: fmt.Sprintf("You were assigned task #%d", task.ID),
+		Body:    task.Title,
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("Failed to queue the assignment notification", err, "task_id", task.ID, "assignee_id", *task.AssigneeID)
+	}
+}
+
+// Duplicate copies a task, and optionally its subtasks, checklist, tags,
+// attachments and comments, next to the original. The actor needs to be
+// allowed to create tasks where the original lives.
+func (s *TaskService) Duplicate(ctx context.Context, actor policy.Actor, id int, options DuplicateOptions) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Duplicate")
+	defer span.End()
+
+	source, err := s.find(ctx, actor, id, policy.ViewTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCreate(actor, source); err != nil {
+		return nil, err
+	}
+
+	sources := []repository.Task{*source}
+	if options.Subtasks {
+		subtasks, err := s.subtree(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, subtasks...)
+	}
+
+	last, err := s.repo.LastRank(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ranks := rank.After(last, len(sources))
+
+	// Copies are built in the order of sources, which lists parents first
+	now := time.Now()
+	copies := make(map[int]*repository.TaskTree, len(sources))
+	var root repository.TaskTree
+	for i, original := range sources {
+		task := &repository.Task{
+			Title:           original.Title,
+			Description:     original.Description,
+			Priority:        original.Priority,
+			Status:          StatusTodo,
+			Rank:            ranks[i],
+			Recurrence:      original.Recurrence,
+			CreatorID:       actor.ID(),
+			WorkspaceID:     original.WorkspaceID,
+			ProjectID:       original.ProjectID,
+			ParentID:        original.ParentID,
+			EstimateMinutes: original.EstimateMinutes,
+			DueDate:         original.DueDate,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if options.Tags {
+			task.Tags = original.Tags
+		}
+
+		if i == 0 {
+			task.Title = original.Title + " (copy)"
+			if options.Title != "" {
+				task.Title = options.Title
+			}
+			root.Task = task
+			copies[original.ID] = &root
+			continue
+		}
+
+		parent := copies[*original.ParentID]
+		parent.Subtasks = append(parent.Subtasks, repository.TaskTree{Task: task})
+		copies[original.ID] = &parent.Subtasks[len(parent.Subtasks)-1]
+	}
+
+	// Subtask slices may have been reallocated while building, so the copies
+	// are collected from the final tree
+	if err := s.repo.CreateTrees(ctx, []repository.TaskTree{root}); err != nil {
+		return nil, err
+	}
+	created := map[int]*repository.Task{}
+	collectCopies(sources, root, created)
+
+	// Details are copied after the tasks were stored; if that fails, the
+	// whole copy is removed again rather than left half-finished
+	for _, original := range sources {
+		if err := s.copyDetails(ctx, actor, original.ID, created[original.ID], options); err != nil {
+			s.discardCopy(ctx, root.Task.ID)
+			return nil, err
+		}
+	}
+
+	return s.repo.FindByID(ctx, root.Task.ID)
+}
+
+// copyDetails copies the selected details of a task to its duplicate and
+// records where the duplicate came from
+func (s *TaskService) copyDetails(ctx context.Context, actor policy.Actor, fromID int, to *repository.Task, options DuplicateOptions) error {
+	if options.Checklist {
+		items, err := s.checklists.FindByTask(fromID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			_, err := s.checklists.Create(&repository.ChecklistItem{
+				TaskID:    to.ID,
+				Text:      item.Text,
+				CreatedAt: to.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if options.Comments {
+		comments, err := s.comments.FindByTask(fromID)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			comment.TaskID = to.ID
+			if _, err := s.comments.Create(&comment); err != nil {
+				return err
+			}
+		}
+	}
+
+	if options.Attachments {
+		if err := s.attachments.CopyTask(ctx, actor, fromID, to.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.history.Create(&repository.TaskHistoryEntry{
+		TaskID:    to.ID,
+		ActorID:   actor.ID(),
+		Action:    repository.HistoryDuplicated,
+		OldValue:  strconv.Itoa(fromID),
+		NewValue:  strconv.Itoa(to.ID),
+		CreatedAt: to.CreatedAt,
+	})
+	return err
+}
+
+// discardCopy removes a partially duplicated task tree along with the
+// content of the attachments copied so far. A failure is only logged: the
+// caller already reports the error that made the copy unusable.
+func (s *TaskService) discardCopy(ctx context.Context, id int) {
+	// The cleanup also runs when the request was cancelled halfway
+	ctx = context.WithoutCancel(ctx)
+
+	keys, err := s.storageKeys(ctx, id)
+	if err == nil {
+		err = s.repo.Delete(ctx, id)
+	}
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("Failed to remove an incomplete task copy", err, "task_id", id)
+		return
+	}
+
+	s.attachments.Discard(ctx, keys)
+}
+
+// MoveToProject moves a task and its subtasks into another project. The
+// actor must be allowed to create tasks in the destination project and,
+// when the task leaves its workspace, to delete it from the source.
+// Assignees who are not members of the destination workspace are unassigned.
+func (s *TaskService) MoveToProject(ctx context.Context, actor policy.Actor, id int, projectID int) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.MoveToProject")
+	defer span.End()
+
+	task, err := s.find(ctx, actor, id, policy.EditTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.ParentID != nil {
+		return nil, invalid("subtasks move together with their parent task")
+	}
+
+	project, err := s.projects.FindByID(projectID)
+	if err == repository.ErrProjectNotFound {
+		return nil, invalid("unknown project")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Project(actor, project, policy.CreateTask); err != nil {
+		return nil, err
+	}
+
+	if sameUser(task.ProjectID, &project.ID) {
+		return task, nil
+	}
+
+	if !sameUser(task.WorkspaceID, project.WorkspaceID) {
+		if err := s.policy.Task(actor, task, policy.DeleteTask); err != nil {
+			return nil, err
+		}
+	}
+
+	subtasks, err := s.subtree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole subtree moves in one transaction so that a failure cannot
+	// leave it split across projects
+	tasks := append([]repository.Task{*task}, subtasks...)
+	var entries []repository.TaskHistoryEntry
+	for i := range tasks {
+		moved, err := s.moveOne(actor, &tasks[i], project)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, moved...)
+	}
+
+	if err := s.repo.UpdateWithHistory(ctx, tasks, entries); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// moveOne points a single task at a project, unassigning it if the assignee
+// cannot follow, and returns the history entries recording the change
+func (s *TaskService) moveOne(actor policy.Actor, task *repository.Task, project *repository.Project) ([]repository.TaskHistoryEntry, error) {
+	now := time.Now()
+	entries := []repository.TaskHistoryEntry{{
+		TaskID:    task.ID,
+		ActorID:   actor.ID(),
+		Action:    repository.HistoryMoved,
+		OldValue:  formatUserID(task.ProjectID),
+		NewValue:  strconv.Itoa(project.ID),
+		CreatedAt: now,
+	}}
+
+	task.ProjectID = &project.ID
+	task.WorkspaceID = project.WorkspaceID
+	task.UpdatedAt = now
+
+	if task.AssigneeID != nil {
+		if err := s.checkAssignee(task, *task.AssigneeID); err != nil {
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				return nil, err
+			}
+			entries = append(entries, repository.TaskHistoryEntry{
+				TaskID:    task.ID,
+				ActorID:   actor.ID(),
+				Action:    repository.HistoryAssigned,
+				OldValue:  formatUserID(task.AssigneeID),
+				CreatedAt: now,
+			})
+			task.AssigneeID = nil
+		}
+	}
+
+	return entries, nil
+}
+
+// checkCreate verifies that the actor may create tasks next to task
+func (s *TaskService) checkCreate(actor policy.Actor, task *repository.Task) error {
+	if task.ProjectID != nil {
+		project, err := s.projects.FindByID(*task.ProjectID)
+		if err != nil {
+			return err
+		}
+		return s.policy.Project(actor, project, policy.CreateTask)
+	}
+
+	if task.WorkspaceID != nil {
+		return s.policy.Workspace(actor, *task.WorkspaceID, policy.CreateTask)
+	}
+
+	return nil
+}
+
+// subtree returns all subtasks below a task, parents before their subtasks
+func (s *TaskService) subtree(ctx context.Context, id int) ([]repository.Task, error) {
+	subtasks, err := s.repo.FindAll(ctx, repository.TaskFilter{ParentID: &id, OrderByRank: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var all []repository.Task
+	for _, subtask := range subtasks {
+		below, err := s.subtree(ctx, subtask.ID)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, subtask)
+		all = append(all, below...)
+	}
+
+	return all, nil
+}
+
+// Snooze hides a task from lists until a later time, given either as an
+// offset from now like "4h", "3d" or "1w", or as a date (YYYY-MM-DD, in UTC)
+// or RFC 3339 timestamp
+func (s *TaskService) Snooze(ctx context.Context, actor policy.Actor, id int, until string) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Snooze")
+	defer span.End()
+
+	task, err := s.find(ctx, actor, id, policy.EditTask)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	wake, err := parseSnooze(until, now)
+	if err != nil {
+		return nil, err
+	}
+	if !wake.After(now) {
+		return nil, invalid("a task can only be snoozed until a time in the future")
+	}
+
+	return s.setHiddenUntil(ctx, actor, task, &wake, repository.HistorySnoozed)
+}
+
+// Unsnooze makes a deferred task available again right away
+func (s *TaskService) Unsnooze(ctx context.Context, actor policy.Actor, id int) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Unsnooze")
+	defer span.End()
+
+	task, err := s.find(ctx, actor, id, policy.EditTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.HiddenUntil == nil {
+		return task, nil
+	}
+
+	return s.setHiddenUntil(ctx, actor, task, nil, repository.HistoryWoken)
+}
+
+// WakeUp clears the deferral of tasks whose hidden_until has passed and
+// returns them, so that their availability can be announced once. When ctx
+// is cancelled or a task cannot be updated, it returns the tasks woken so
+// far together with the error.
+func (s *TaskService) WakeUp(ctx context.Context, actor policy.Actor, now time.Time) ([]repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.WakeUp")
+	defer span.End()
+
+	now = now.UTC()
+	tasks, err := s.repo.FindAll(ctx, repository.TaskFilter{WokenBy: &now, OrderByRank: true})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tasks {
+		if err := ctx.Err(); err != nil {
+			return tasks[:i], err
+		}
+		if _, err := s.setHiddenUntil(ctx, actor, &tasks[i], nil, repository.HistoryWoken); err != nil {
+			return tasks[:i], err
+		}
+	}
+
+	return tasks, nil
+}
+
+// setHiddenUntil sets the hidden_until of a task and records the change
+func (s *TaskService) setHiddenUntil(ctx context.Context, actor policy.Actor, task *repository.Task, until *time.Time, action string) (*repository.Task, error) {
+	entry := &repository.TaskHistoryEntry{
+		TaskID:    task.ID,
+		ActorID:   actor.ID(),
+		Action:    action,
+		OldValue:  formatTime(task.HiddenUntil),
+		NewValue:  formatTime(until),
+		CreatedAt: time.Now(),
+	}
+
+	task.HiddenUntil = until
+	task.UpdatedAt = entry.CreatedAt
+	if _, err := s.repo.Update(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.history.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// History returns the recorded changes of a task
+func (s *TaskService) History(ctx context.Context, actor policy.Actor, id int) ([]repository.TaskHistoryEntry, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.History")
+	defer span.End()
+
+	if _, err := s.find(ctx, actor, id, policy.ViewTask); err != nil {
+		return nil, err
+	}
+	return s.history.FindByTask(id)
+}
+
+// Delete removes a task and its subtasks along with the stored content of
+// their attachments. The rows go first; content that cannot be removed
+// afterwards is logged as orphaned.
+func (s *TaskService) Delete(ctx context.Context, actor policy.Actor, id int) error {
+	ctx, span := tracer.Start(ctx, "TaskService.Delete")
+	defer span.End()
+
+	if _, err := s.find(ctx, actor, id, policy.DeleteTask); err != nil {
+		return err
+	}
+
+	keys, err := s.storageKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.attachments.Discard(ctx, keys)
+	return nil
+}
+
+// storageKeys returns the keys of the attachment content of a task and its
+// subtasks
+func (s *TaskService) storageKeys(ctx context.Context, id int) ([]string, error) {
+	keys, err := s.attachments.StorageKeys(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subtasks, err := s.repo.FindAll(ctx, repository.TaskFilter{ParentID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subtask := range subtasks {
+		subtaskKeys, err := s.storageKeys(ctx, subtask.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, subtaskKeys...)
+	}
+
+	return keys, nil
+}
+
+// Complete marks a task as completed. With RequireChecklistComplete set,
+// every checklist item must be checked first.
+func (s *TaskService) Complete(ctx context.Context, actor policy.Actor, id int) (*repository.Task, error) {
+	ctx, span := tracer.Start(ctx, "TaskService.Complete")
+	defer span.End()
+
+	task, err := s.find(ctx, actor, id, policy.EditTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.options.RequireChecklistComplete && task.Checklist.Done < task.Checklist.Total {
+		unchecked := task.Checklist.Total - task.Checklist.Done
+		return nil, conflict(fmt.Sprintf("task #%d has %d unchecked checklist items", task.ID, unchecked))
+	}
+
 	task.Completed = true
+	task.Status = StatusDone
 	task.CompletedAt = func() *time.Time { now := time.Now(); return &now }()
 	task.UpdatedAt = time.Now()
 
-	return s.repo.Update(task)
+	return s.repo.Update(ctx, task)
+}
+
+// checkAssignee verifies that assigneeID exists and, for workspace tasks,
+// belongs to the task's workspace
+func (s *TaskService) checkAssignee(task *repository.Task, assigneeID int) error {
+	if _, err := s.users.FindByID(assigneeID); err == repository.ErrUserNotFound {
+		return invalid("unknown assignee")
+	} else if err != nil {
+		return err
+	}
+
+	if task.WorkspaceID == nil {
+		return nil
+	}
+
+	_, err := s.workspaces.FindMember(*task.WorkspaceID, assigneeID)
+	if err == repository.ErrMemberNotFound {
+		return invalid("assignee is not a member of the task's workspace")
+	}
+	return err
+}
+
+// checkTags rejects tags that cannot be stored in the comma separated tags column
+func checkTags(tags []string) error {
+	for _, tag := range tags {
+		if strings.Contains(tag, ",") {
+			return invalid(fmt.Sprintf("invalid tag %q: tags must not contain commas", tag))
+		}
+	}
+	return nil
+}
+
+func checkEstimate(minutes *int) error {
+	if minutes != nil && *minutes < 0 {
+		return invalid("estimate_minutes must not be negative")
+	}
+	return nil
+}
+
+// collectCopies maps the IDs of the original tasks to their created copies.
+// Both sources and the tree list parents before their subtasks.
+func collectCopies(sources []repository.Task, tree repository.TaskTree, copies map[int]*repository.Task) []repository.Task {
+	copies[sources[0].ID] = tree.Task
+	rest := sources[1:]
+	for _, subtree := range tree.Subtasks {
+		rest = collectCopies(rest, subtree, copies)
+	}
+	return rest
+}
+
+// parseSnooze interprets the target of a snooze relative to now
+func parseSnooze(until string, now time.Time) (time.Time, error) {
+	if dueOffsetPattern.MatchString(until) {
+		return now.Add(parseDueOffset(until)), nil
+	}
+	if date, err := time.Parse("2006-01-02", until); err == nil {
+		return date, nil
+	}
+	if t, err := time.Parse(time.RFC3339, until); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, invalid(fmt.Sprintf("invalid snooze %q, expected an offset like 3d, a date (YYYY-MM-DD) or an RFC 3339 timestamp", until))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func sameUser(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatUserID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
 }
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/rank"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Limits on the size of a template's task tree
+const (
+	maxTemplateTasks = 200
+	maxTemplateDepth = 5
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	dueOffsetPattern   = regexp.MustCompile(`^([+-]?\d+)([hdw])$`)
+)
+
+// TemplateInput holds the fields of a new or updated template
+type TemplateInput struct {
+	Name        string
+	Description string
+	WorkspaceID *int
+	Tasks       []repository.TemplateTask
+}
+
+// InstantiateInput holds the values used to create tasks from a template.
+// AnchorDate (YYYY-MM-DD, default today) is the reference for due offsets.
+// Without a project or workspace the tasks go to the template's workspace.
+type InstantiateInput struct {
+	Variables   map[string]string
+	AnchorDate  string
+	WorkspaceID *int
+	ProjectID   *int
+}
+
+// TemplateView is a template together with the placeholders it uses
+type TemplateView struct {
+	*repository.Template
+	Variables []string `json:"variables"`
+}
+
+// TemplateService handles business logic for task templates
+type TemplateService struct {
+	repo     *repository.TemplateRepository
+	tasks    *repository.TaskRepository
+	projects *repository.ProjectRepository
+	policy   *policy.Policy
+}
+
+// NewTemplateService creates a new TemplateService
+func NewTemplateService(repos *repository.Repositories, policy *policy.Policy) *TemplateService {
+	return &TemplateService{
+		repo:     repos.Templates,
+		tasks:    repos.Tasks,
+		projects: repos.Projects,
+		policy:   policy,
+	}
+}
+
+// Create adds a new template
+func (s *TemplateService) Create(actor policy.Actor, input TemplateInput) (*TemplateView, error) {
+	if actor.System {
+		return nil, invalid("templates must be created by a user")
+	}
+	if err := checkTemplate(input); err != nil {
+		return nil, err
+	}
+
+	template := &repository.Template{
+		Name:        input.Name,
+		Description: input.Description,
+		WorkspaceID: input.WorkspaceID,
+		OwnerID:     actor.ID(),
+		Tasks:       input.Tasks,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.authorize(actor, template, policy.CreateTask); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(template)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateView(created), nil
+}
+
+// List returns the templates the actor can see
+func (s *TemplateService) List(actor policy.Actor) ([]TemplateView, error) {
+	templates, err := s.repo.FindVisibleTo(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]TemplateView, len(templates))
+	for i := range templates {
+		views[i] = *templateView(&templates[i])
+	}
+
+	return views, nil
+}
+
+// Get returns a template
+func (s *TemplateService) Get(actor policy.Actor, id int) (*TemplateView, error) {
+	template, err := s.find(actor, id, policy.ViewTask)
+	if err != nil {
+		return nil, err
+	}
+	return templateView(template), nil
+}
+
+// Update replaces the name, description and tasks of a template
+func (s *TemplateService) Update(actor policy.Actor, id int, input TemplateInput) (*TemplateView, error) {
+	template, err := s.find(actor, id, policy.CreateTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTemplate(input); err != nil {
+		return nil, err
+	}
+
+	template.Name = input.Name
+	template.Description = input.Description
+	template.Tasks = input.Tasks
+	template.UpdatedAt = time.Now()
+
+	updated, err := s.repo.Update(template)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateView(updated), nil
+}
+
+// Delete removes a template
+func (s *TemplateService) Delete(actor policy.Actor, id int) error {
+	if _, err := s.find(actor, id, policy.CreateTask); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Instantiate creates the template's task tree in a single transaction and
+// returns the created tasks, parents before their subtasks
+func (s *TemplateService) Instantiate(ctx context.Context, actor policy.Actor, id int, input InstantiateInput) ([]repository.Task, error) {
+	template, err := s.find(actor, id, policy.ViewTask)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, name := range placeholders(template.Tasks) {
+		if _, ok := input.Variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, invalid("missing values for variables: " + strings.Join(missing, ", "))
+	}
+
+	anchor := time.Now().UTC().Truncate(24 * time.Hour)
+	if input.AnchorDate != "" {
+		anchor, err = time.Parse("2006-01-02", input.AnchorDate)
+		if err != nil {
+			return nil, invalid("invalid anchor date format, expected YYYY-MM-DD")
+		}
+	}
+
+	workspaceID, projectID, err := s.target(actor, template, input)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := s.tasks.LastRank(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &treeBuilder{
+		now:       time.Now(),
+		anchor:    anchor,
+		variables: input.Variables,
+		ranks:     rank.After(last, countTemplateTasks(template.Tasks)),
+		base: repository.Task{
+			CreatorID:   actor.ID(),
+			WorkspaceID: workspaceID,
+			ProjectID:   projectID,
+			Status:      StatusTodo,
+		},
+	}
+	trees := b.build(template.Tasks)
+
+	if err := s.tasks.CreateTrees(ctx, trees); err != nil {
+		return nil, err
+	}
+
+	created := make([]repository.Task, len(b.created))
+	for i, task := range b.created {
+		created[i] = *task
+	}
+
+	return created, nil
+}
+
+// target resolves and authorizes the workspace and project receiving the
+// instantiated tasks
+func (s *TemplateService) target(actor policy.Actor, template *repository.Template, input InstantiateInput) (*int, *int, error) {
+	workspaceID := input.WorkspaceID
+
+	if input.ProjectID != nil {
+		project, err := s.projects.FindByID(*input.ProjectID)
+		if err == repository.ErrProjectNotFound {
+			return nil, nil, invalid("unknown project")
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if err := s.policy.Project(actor, project, policy.CreateTask); err != nil {
+			return nil, nil, err
+		}
+		if workspaceID != nil && !sameUser(workspaceID, project.WorkspaceID) {
+			return nil, nil, invalid("the project does not belong to the given workspace")
+		}
+		return project.WorkspaceID, input.ProjectID, nil
+	}
+
+	if workspaceID == nil {
+		workspaceID = template.WorkspaceID
+	}
+	if workspaceID != nil {
+		if err := s.policy.Workspace(actor, *workspaceID, policy.CreateTask); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return workspaceID, nil, nil
+}
+
+// find loads a template and checks that the actor may perform action on it
+func (s *TemplateService) find(actor policy.Actor, id int, action policy.Action) (*repository.Template, error) {
+	template, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(actor, template, action); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// authorize checks an action on a template: workspace templates follow the
+// actor's role, personal templates belong to their owner
+func (s *TemplateService) authorize(actor policy.Actor, template *repository.Template, action policy.Action) error {
+	if actor.System {
+		return nil
+	}
+
+	if template.WorkspaceID != nil {
+		return s.policy.Workspace(actor, *template.WorkspaceID, action)
+	}
+
+	if template.OwnerID != nil && *template.OwnerID == actor.UserID {
+		return nil
+	}
+
+	return &policy.ForbiddenError{
+		Action: action,
+		Reason: fmt.Sprintf("template #%d is a personal template of another user", template.ID),
+	}
+}
+
+// treeBuilder turns template tasks into tasks ready to be stored
+type treeBuilder struct {
+	now       time.Time
+	anchor    time.Time
+	variables map[string]string
+	ranks     []string
+	base      repository.Task
+	created   []*repository.Task
+}
+
+func (b *treeBuilder) build(tasks []repository.TemplateTask) []repository.TaskTree {
+	trees := make([]repository.TaskTree, 0, len(tasks))
+
+	for _, t := range tasks {
+		task := b.base
+		task.Title = b.fill(t.Title)
+		task.Description = b.fill(t.Description)
+		task.Tags = append(repository.StringList{}, t.Tags...)
+		task.Priority = t.Priority
+		task.EstimateMinutes = t.EstimateMinutes
+		task.Rank = b.ranks[len(b.created)]
+		task.CreatedAt = b.now
+		task.UpdatedAt = b.now
+
+		if t.DueOffset != "" {
+			due := b.anchor.Add(parseDueOffset(t.DueOffset))
+			task.DueDate = &due
+		}
+
+		b.created = append(b.created, &task)
+		trees = append(trees, repository.TaskTree{
+			Task:     &task,
+			Subtasks: b.build(t.Subtasks),
+		})
+	}
+
+	return trees
+}
+
+func (b *treeBuilder) fill(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		return b.variables[name]
+	})
+}
+
+// checkTemplate validates a template and normalizes task priorities
+func checkTemplate(input TemplateInput) error {
+	if input.Name == "" {
+		return invalid("name is required")
+	}
+	if len(input.Tasks) == 0 {
+		return invalid("a template needs at least one task")
+	}
+	if countTemplateTasks(input.Tasks) > maxTemplateTasks {
+		return invalid(fmt.Sprintf("templates are limited to %d tasks", maxTemplateTasks))
+	}
+	return checkTemplateTasks(input.Tasks, 1)
+}
+
+func checkTemplateTasks(tasks []repository.TemplateTask, depth int) error {
+	if depth > maxTemplateDepth {
+		return invalid(fmt.Sprintf("subtasks may be nested at most %d levels deep", maxTemplateDepth))
+	}
+
+	for i := range tasks {
+		t := &tasks[i]
+		if t.Title == "" {
+			return invalid("every template task needs a title")
+		}
+
+		if t.Priority == "" {
+			t.Priority = PriorityNormal
+		}
+		priority, err := parsePriority(strings.ToLower(t.Priority))
+		if err != nil {
+			return err
+		}
+		t.Priority = priority
+
+		if t.DueOffset != "" && !dueOffsetPattern.MatchString(t.DueOffset) {
+			return invalid(fmt.Sprintf("invalid due offset %q, expected a number of hours, days or weeks like 4h, 3d or -1w", t.DueOffset))
+		}
+		if err := checkEstimate(t.EstimateMinutes); err != nil {
+			return err
+		}
+		if err := checkTags(t.Tags); err != nil {
+			return err
+		}
+
+		if err := checkTemplateTasks(t.Subtasks, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseDueOffset converts a validated offset like "3d" into a duration
+func parseDueOffset(offset string) time.Duration {
+	m := dueOffsetPattern.FindStringSubmatch(offset)
+	n, _ := strconv.Atoi(m[1])
+
+	switch m[2] {
+	case "h":
+		return time.Duration(n) * time.Hour
+	case "w":
+		return time.Duration(n) * 7 * 24 * time.Hour
+	default:
+		return time.Duration(n) * 24 * time.Hour
+	}
+}
+
+func countTemplateTasks(tasks []repository.TemplateTask) int {
+	count := len(tasks)
+	for _, t := range tasks {
+		count += countTemplateTasks(t.Subtasks)
+	}
+	return count
+}
+
+// placeholders returns the sorted names of the placeholders used in a task tree
+func placeholders(tasks []repository.TemplateTask) []string {
+	seen := map[string]bool{}
+	var collect func([]repository.TemplateTask)
+	collect = func(tasks []repository.TemplateTask) {
+		for _, t := range tasks {
+			for _, text := range []string{t.Title, t.Description} {
+				for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+					seen[m[1]] = true
+				}
+			}
+			collect(t.Subtasks)
+		}
+	}
+	collect(tasks)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func templateView(template *repository.Template) *TemplateView {
+	return &TemplateView{Template: template, Variables: placeholders(template.Tasks)}
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// Time report groupings
+const (
+	GroupByDay     = "day"
+	GroupByProject = "project"
+	GroupByTag     = "tag"
+)
+
+// Report keys of entries without a project or tag
+const (
+	noProject = "(no project)"
+	noTag     = "(untagged)"
+)
+
+// TimeEntryInput holds the fields of a manually entered time entry. A nil
+// EndedAt on updates leaves the end unchanged.
+type TimeEntryInput struct {
+	StartedAt time.Time
+	EndedAt   *time.Time
+	// Note is left unchanged by updates when nil
+	Note *string
+}
+
+// TaskTime summarizes the time tracked on a task
+type TaskTime struct {
+	EstimateMinutes *int                   `json:"estimate_minutes,omitempty"`
+	TrackedMinutes  int                    `json:"tracked_minutes"`
+	Entries         []repository.TimeEntry `json:"entries"`
+}
+
+// ReportQuery selects and groups the entries of a time report. To is exclusive.
+type ReportQuery struct {
+	From      *time.Time
+	To        *time.Time
+	UserID    *int
+	ProjectID *int
+	GroupBy   string
+}
+
+// TimeReportRow is the time tracked for one group of a report
+type TimeReportRow struct {
+	Key     string `json:"key"`
+	Minutes int    `json:"minutes"`
+	Entries int    `json:"entries"`
+}
+
+// TimeReport is the tracked time aggregated by day, project or tag
+type TimeReport struct {
+	GroupBy      string          `json:"group_by"`
+	TotalMinutes int             `json:"total_minutes"`
+	Rows         []TimeReportRow `json:"rows"`
+}
+
+// TimeService handles business logic for time tracking
+type TimeService struct {
+	repo   *repository.TimeEntryRepository
+	tasks  *repository.TaskRepository
+	policy *policy.Policy
+}
+
+// NewTimeService creates a new TimeService
+func NewTimeService(repos *repository.Repositories, policy *policy.Policy) *TimeService {
+	return &TimeService{
+		repo:   repos.TimeEntries,
+		tasks:  repos.Tasks,
+		policy: policy,
+	}
+}
+
+// Start starts a timer on a task. A user has at most one running timer.
+func (s *TimeService) Start(ctx context.Context, actor policy.Actor, taskID int, note string) (*repository.TimeEntry, error) {
+	if actor.System {
+		return nil, invalid("timers must be started by a user")
+	}
+	if _, err := s.task(ctx, actor, taskID, policy.TrackTime); err != nil {
+		return nil, err
+	}
+
+	running, err := s.repo.FindRunning(actor.UserID)
+	if err == nil {
+		return nil, conflict(fmt.Sprintf("a timer is already running on task #%d", running.TaskID))
+	} else if err != repository.ErrTimeEntryNotFound {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry, err := s.repo.Create(&repository.TimeEntry{
+		TaskID:    taskID,
+		UserID:    actor.UserID,
+		StartedAt: now,
+		Note:      note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err == repository.ErrTimerRunning {
+		// Another timer was started since the check above
+		return nil, conflict("a timer is already running")
+	}
+	return entry, err
+}
+
+// Stop stops the actor's running timer on a task
+func (s *TimeService) Stop(ctx context.Context, actor policy.Actor, taskID int) (*repository.TimeEntry, error) {
+	if _, err := s.task(ctx, actor, taskID, policy.TrackTime); err != nil {
+		return nil, err
+	}
+
+	running, err := s.repo.FindRunning(actor.UserID)
+	if err == repository.ErrTimeEntryNotFound || (err == nil && running.TaskID != taskID) {
+		return nil, conflict(fmt.Sprintf("no timer is running on task #%d", taskID))
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	running.EndedAt = &now
+	running.UpdatedAt = now
+
+	return s.repo.Update(running)
+}
+
+// List returns the time entries of a task along with its estimate
+func (s *TimeService) List(ctx context.Context, actor policy.Actor, taskID int) (*TaskTime, error) {
+	task, err := s.task(ctx, actor, taskID, policy.ViewTask)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.FindByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TaskTime{EstimateMinutes: task.EstimateMinutes, Entries: entries}
+	now := time.Now()
+	for i := range entries {
+		summary.TrackedMinutes += entries[i].Minutes(now)
+	}
+
+	return summary, nil
+}
+
+// Create records a finished time entry on a task for the actor
+func (s *TimeService) Create(ctx context.Context, actor policy.Actor, taskID int, input TimeEntryInput) (*repository.TimeEntry, error) {
+	if actor.System {
+		return nil, invalid("time entries must belong to a user")
+	}
+	if input.EndedAt == nil {
+		return nil, invalid("ended_at is required, use the timer to track ongoing work")
+	}
+	if err := checkPeriod(input.StartedAt, input.EndedAt); err != nil {
+		return nil, err
+	}
+	if _, err := s.task(ctx, actor, taskID, policy.TrackTime); err != nil {
+		return nil, err
+	}
+
+	var note string
+	if input.Note != nil {
+		note = *input.Note
+	}
+
+	now := time.Now()
+	return s.repo.Create(&repository.TimeEntry{
+		TaskID:    taskID,
+		UserID:    actor.UserID,
+		StartedAt: input.StartedAt,
+		EndedAt:   input.EndedAt,
+		Note:      note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// Update edits one of the actor's time entries
+func (s *TimeService) Update(ctx context.Context, actor policy.Actor, id int, input TimeEntryInput) (*repository.TimeEntry, error) {
+	entry, err := s.entry(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.StartedAt.IsZero() {
+		entry.StartedAt = input.StartedAt
+	}
+	if input.EndedAt != nil {
+		entry.EndedAt = input.EndedAt
+	}
+	if input.Note != nil {
+		entry.Note = *input.Note
+	}
+
+	if err := checkPeriod(entry.StartedAt, entry.EndedAt); err != nil {
+		return nil, err
+	}
+	entry.UpdatedAt = time.Now()
+
+	return s.repo.Update(entry)
+}
+
+// Delete removes one of the actor's time entries
+func (s *TimeService) Delete(ctx context.Context, actor policy.Actor, id int) error {
+	if _, err := s.entry(ctx, actor, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Report aggregates the finished time entries on tasks visible to the actor.
+// Days are UTC calendar days of the entry start; an entry counts towards
+// every tag of its task.
+func (s *TimeService) Report(ctx context.Context, actor policy.Actor, query ReportQuery) (*TimeReport, error) {
+	if query.GroupBy == "" {
+		query.GroupBy = GroupByDay
+	}
+	if query.GroupBy != GroupByDay && query.GroupBy != GroupByProject && query.GroupBy != GroupByTag {
+		return nil, invalid("group_by must be one of day, project, tag")
+	}
+
+	filter := repository.ReportFilter{
+		From:      query.From,
+		To:        query.To,
+		UserID:    query.UserID,
+		ProjectID: query.ProjectID,
+	}
+	if !actor.System {
+		filter.VisibleTo = &actor.UserID
+	}
+
+	entries, err := s.repo.FindForReport(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TimeReport{GroupBy: query.GroupBy, Rows: []TimeReportRow{}}
+	rows := map[string]*TimeReportRow{}
+	add := func(key string, minutes int) {
+		row, ok := rows[key]
+		if !ok {
+			row = &TimeReportRow{Key: key}
+			rows[key] = row
+		}
+		row.Minutes += minutes
+		row.Entries++
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		minutes := entry.Minutes(*entry.EndedAt)
+		report.TotalMinutes += minutes
+
+		switch query.GroupBy {
+		case GroupByDay:
+			add(entry.StartedAt.UTC().Format("2006-01-02"), minutes)
+		case GroupByProject:
+			key := noProject
+			if entry.ProjectName != nil {
+				key = *entry.ProjectName
+			}
+			add(key, minutes)
+		case GroupByTag:
+			if len(entry.Tags) == 0 {
+				add(noTag, minutes)
+			}
+			for _, tag := range entry.Tags {
+				add(tag, minutes)
+			}
+		}
+	}
+
+	for _, row := range rows {
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Key < report.Rows[j].Key
+	})
+
+	return report, nil
+}
+
+// task loads a task and checks that the actor may perform action on it
+func (s *TimeService) task(ctx context.Context, actor policy.Actor, taskID int, action policy.Action) (*repository.Task, error) {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Task(actor, task, action); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// entry loads a time entry owned by the actor
+func (s *TimeService) entry(ctx context.Context, actor policy.Actor, id int) (*repository.TimeEntry, error) {
+	entry, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actor.System && entry.UserID != actor.UserID {
+		return nil, &policy.ForbiddenError{
+			Action: policy.TrackTime,
+			Reason: "you can only change your own time entries",
+		}
+	}
+
+	return entry, nil
+}
+
+func checkPeriod(start time.Time, end *time.Time) error {
+	if start.IsZero() {
+		return invalid("started_at is required")
+	}
+	if end != nil && !end.After(start) {
+		return invalid("ended_at must be after started_at")
+	}
+	return nil
+}
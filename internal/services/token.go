@@ -0,0 +1,153 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// APITokenPrefix marks personal API tokens so they can be told apart from JWTs
+const APITokenPrefix = "tm_"
+
+// Token lifetimes
+const (
+	defaultTokenDays = 90
+	maxTokenDays     = 365
+)
+
+// Scopes grant API tokens read or write access to a resource
+var Scopes = []string{
+	"tasks:read", "tasks:write",
+	"workspaces:read", "workspaces:write",
+	"notifications:read", "notifications:write",
+}
+
+// TokenService manages personal API tokens
+type TokenService struct {
+	repo  *repository.TokenRepository
+	users *repository.UserRepository
+}
+
+// NewTokenService creates a new TokenService
+func NewTokenService(repo *repository.TokenRepository, users *repository.UserRepository) *TokenService {
+	return &TokenService{
+		repo:  repo,
+		users: users,
+	}
+}
+
+// Create mints a new token for the actor. The plaintext secret is returned
+// only once; afterwards only its hash is known.
+func (s *TokenService) Create(actor policy.Actor, name string, scopes []string, expiresInDays int) (string, *repository.APIToken, error) {
+	if actor.System {
+		return "", nil, invalid("tokens must belong to a user")
+	}
+	if name == "" {
+		return "", nil, invalid("name is required")
+	}
+	if len(scopes) == 0 {
+		return "", nil, invalid("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !validScope(scope) {
+			return "", nil, invalid("unknown scope " + scope + ", expected one of " + strings.Join(Scopes, ", "))
+		}
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = defaultTokenDays
+	}
+	if expiresInDays < 0 || expiresInDays > maxTokenDays {
+		return "", nil, invalid("expires_in_days must be between 1 and 365")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	plaintext := APITokenPrefix + hex.EncodeToString(secret)
+
+	now := time.Now()
+	token, err := s.repo.Create(&repository.APIToken{
+		UserID:    actor.UserID,
+		Name:      name,
+		Prefix:    plaintext[:len(APITokenPrefix)+8],
+		TokenHash: hashToken(plaintext),
+		Scopes:    scopes,
+		ExpiresAt: now.AddDate(0, 0, expiresInDays),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, token, nil
+}
+
+// List returns the actor's tokens
+func (s *TokenService) List(actor policy.Actor) ([]repository.APIToken, error) {
+	return s.repo.FindByUser(actor.UserID)
+}
+
+// Revoke disables one of the actor's tokens
+func (s *TokenService) Revoke(actor policy.Actor, id int) error {
+	token, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Other users' tokens are reported as missing rather than forbidden
+	if !actor.System && token.UserID != actor.UserID {
+		return repository.ErrTokenNotFound
+	}
+
+	return s.repo.Revoke(id, time.Now())
+}
+
+// Authenticate resolves a plaintext token to its user and scopes, recording
+// when it was last used
+func (s *TokenService) Authenticate(plaintext string) (*repository.User, []string, error) {
+	token, err := s.repo.FindByHash(hashToken(plaintext))
+	if err == repository.ErrTokenNotFound {
+		return nil, nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || now.After(token.ExpiresAt) {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	user, err := s.users.FindByID(token.UserID)
+	if err == repository.ErrUserNotFound {
+		return nil, nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.repo.Touch(token.ID, now); err != nil {
+		return nil, nil, err
+	}
+
+	return user, token.Scopes, nil
+}
+
+func hashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func validScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"time"
+
+	"golang_task_manager_folder_structure/internal/policy"
+	"golang_task_manager_folder_structure/internal/repository"
+)
+
+// WorkspaceService handles business logic for workspaces and their members
+type WorkspaceService struct {
+	repo   *repository.WorkspaceRepository
+	users  *repository.UserRepository
+	policy *policy.Policy
+}
+
+// NewWorkspaceService creates a new WorkspaceService
+func NewWorkspaceService(repo *repository.WorkspaceRepository, users *repository.UserRepository, policy *policy.Policy) *WorkspaceService {
+	return &WorkspaceService{
+		repo:   repo,
+		users:  users,
+		policy: policy,
+	}
+}
+
+// Create adds a new workspace owned by the actor
+func (s *WorkspaceService) Create(actor policy.Actor, name string) (*repository.Workspace, error) {
+	if name == "" {
+		return nil, invalid("name is required")
+	}
+	if actor.System {
+		return nil, invalid("workspaces must be created by a user")
+	}
+
+	workspace := &repository.Workspace{
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+
+	return s.repo.Create(workspace, actor.UserID, policy.RoleOwner)
+}
+
+// List returns the workspaces the actor belongs to
+func (s *WorkspaceService) List(actor policy.Actor) ([]repository.Workspace, error) {
+	return s.repo.FindByUser(actor.UserID)
+}
+
+// Get returns a workspace
+func (s *WorkspaceService) Get(actor policy.Actor, id int) (*repository.Workspace, error) {
+	workspace, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Workspace(actor, id, policy.ViewWorkspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// Members returns the members of a workspace
+func (s *WorkspaceService) Members(actor policy.Actor, id int) ([]repository.WorkspaceMember, error) {
+	if _, err := s.Get(actor, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindMembers(id)
+}
+
+// SaveMember adds username to the workspace or changes their role. Admins
+// manage members and viewers; only owners hand out or take away the owner
+// and admin roles.
+func (s *WorkspaceService) SaveMember(actor policy.Actor, id int, username, role string) (*repository.WorkspaceMember, error) {
+	if !policy.ValidRole(role) {
+		return nil, invalid("role must be one of owner, admin, member, viewer")
+	}
+
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.policy.Workspace(actor, id, policy.ManageMembers); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByUsername(username)
+	if err == repository.ErrUserNotFound {
+		return nil, invalid("unknown user " + username)
+	} else if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.FindMember(id, user.ID)
+	if err != nil && err != repository.ErrMemberNotFound {
+		return nil, err
+	}
+
+	if privileged(role) || (current != nil && privileged(current.Role)) {
+		if err := s.policy.Workspace(actor, id, policy.ManagePrivilege); err != nil {
+			return nil, err
+		}
+	}
+	if current != nil && current.Role == policy.RoleOwner && role != policy.RoleOwner {
+		if err := s.keepOwner(id); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &repository.WorkspaceMember{
+		WorkspaceID: id,
+		UserID:      user.ID,
+		Username:    user.Username,
+		Role:        role,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.SaveMember(member); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindMember(id, user.ID)
+}
+
+// RemoveMember removes memberID from the workspace. Members may always leave.
+func (s *WorkspaceService) RemoveMember(actor policy.Actor, id, memberID int) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return err
+	}
+
+	member, err := s.repo.FindMember(id, memberID)
+	if err != nil {
+		return err
+	}
+
+	if actor.System || actor.UserID != memberID {
+		if err := s.policy.Workspace(actor, id, policy.ManageMembers); err != nil {
+			return err
+		}
+		if privileged(member.Role) {
+			if err := s.policy.Workspace(actor, id, policy.ManagePrivilege); err != nil {
+				return err
+			}
+		}
+	}
+
+	if member.Role == policy.RoleOwner {
+		if err := s.keepOwner(id); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveMember(id, memberID)
+}
+
+// keepOwner refuses changes that would leave a workspace without an owner
+func (s *WorkspaceService) keepOwner(id int) error {
+	members, err := s.repo.FindMembers(id)
+	if err != nil {
+		return err
+	}
+
+	owners := 0
+	for _, m := range members {
+		if m.Role == policy.RoleOwner {
+			owners++
+		}
+	}
+	if owners <= 1 {
+		return invalid("a workspace must keep at least one owner")
+	}
+
+	return nil
+}
+
+func privileged(role string) bool {
+	return role == policy.RoleOwner || role == policy.RoleAdmin
+}
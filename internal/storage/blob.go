@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang_task_manager_folder_structure/internal/config"
+)
+
+// ErrBlobNotFound is returned when a key does not exist in the store
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Blob is a stored object opened for reading. Seeking allows serving range
+// requests without loading the whole object.
+type Blob interface {
+	io.ReadSeekCloser
+}
+
+// BlobStore stores opaque binary objects under string keys
+type BlobStore interface {
+	// Put stores the content of r under key. size may be -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key
+	Get(ctx context.Context, key string) (Blob, error)
+	// Delete removes the object stored under key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore creates the blob store selected by the configuration
+func NewBlobStore(cfg *config.Config) (BlobStore, error) {
+	switch cfg.StorageBackend {
+	case "local":
+		return NewLocalStore(cfg.StorageLocalDir)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage backend %q, expected local or s3", cfg.StorageBackend)
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestS3Store runs against a MinIO (or other S3-compatible) server, e.g.
+//
+//	docker run -p 9000:9000 -e MINIO_ROOT_USER=minio -e MINIO_ROOT_PASSWORD=minio123 minio/minio server /data
+//	S3_TEST_ENDPOINT=localhost:9000 go test ./internal/storage/
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("set S3_TEST_ENDPOINT to run against a MinIO server")
+	}
+
+	store, err := NewS3Store(S3Options{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    "attachments-test",
+		AccessKey: envOr("S3_TEST_ACCESS_KEY", "minio"),
+		SecretKey: envOr("S3_TEST_SECRET_KEY", "minio123"),
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store failed: %v", err)
+	}
+
+	ctx := context.Background()
+	key := "test/" + strings.ReplaceAll(t.Name(), "/", "-")
+	content := "hello, attachments"
+
+	if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	t.Cleanup(func() { store.Delete(ctx, key) })
+
+	blob, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got, err := io.ReadAll(blob)
+	if err != nil || string(got) != content {
+		t.Fatalf("read %q, %v; want %q", got, err, content)
+	}
+
+	// Range requests seek into the object
+	if _, err := blob.Seek(7, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	got, err = io.ReadAll(blob)
+	if err != nil || string(got) != content[7:] {
+		t.Fatalf("read %q after seeking, %v; want %q", got, err, content[7:])
+	}
+	blob.Close()
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("Get after Delete = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("deleting a missing key failed: %v", err)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Options configures tracing
+type Options struct {
+	// ServiceName identifies the binary in traces; OTEL_SERVICE_NAME overrides it
+	ServiceName string
+	// Exporter is none, otlp or stdout
+	Exporter string
+	// Output is stdout, stderr or the path of the file the stdout exporter
+	// writes spans to
+	Output string
+}
+
+// Shutdown flushes the spans that were not exported yet and stops tracing
+type Shutdown func(ctx context.Context) error
+
+// Setup installs the global tracer provider and the W3C trace context and
+// baggage propagators. With the none exporter spans are not recorded, but
+// incoming trace context is still passed on.
+//
+// The otlp exporter sends spans over HTTP and is configured with the
+// standard OTEL_EXPORTER_OTLP_* variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT.
+func Setup(ctx context.Context, options Options) (Shutdown, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		output   io.Closer
+		err      error
+	)
+	switch options.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		var w io.Writer
+		w, output, err = openOutput(options.Output)
+		if err == nil {
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+		}
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %q: must be none, otlp or stdout", options.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", options.Exporter, err)
+	}
+
+	// Later options take precedence, so OTEL_SERVICE_NAME and
+	// OTEL_RESOURCE_ATTRIBUTES override the service name
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", options.ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describe the traced service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if output != nil {
+			output.Close()
+		}
+		return err
+	}, nil
+}
+
+// openOutput returns the destination of the stdout exporter and, for
+// files, what to close once tracing stops
+func openOutput(output string) (io.Writer, io.Closer, error) {
+	switch output {
+	case "", "stdout":
+		return os.Stdout, nil, nil
+	case "stderr":
+		return os.Stderr, nil, nil
+	}
+
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file, nil
+}
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN priority TEXT NOT NULL DEFAULT 'normal';
+ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN recurrence;
+ALTER TABLE tasks DROP COLUMN tags;
+ALTER TABLE tasks DROP COLUMN priority;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    uploader_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_task ON attachments(task_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_checklist_items_task ON checklist_items(task_id, position);
+
+-- +migrate Down
+DROP TABLE IF EXISTS checklist_items;
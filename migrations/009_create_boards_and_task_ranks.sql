@@ -0,0 +1,32 @@
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo';
+ALTER TABLE tasks ADD COLUMN rank TEXT NOT NULL DEFAULT '';
+UPDATE tasks SET status = 'done' WHERE completed;
+-- Existing tasks are ranked on startup in their previous newest-first order
+CREATE INDEX IF NOT EXISTS idx_tasks_rank ON tasks(rank, id);
+
+CREATE TABLE IF NOT EXISTS boards (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+    project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+    owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS board_columns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    wip_limit INTEGER,
+    UNIQUE (board_id, status)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS board_columns;
+DROP TABLE IF EXISTS boards;
+DROP INDEX IF EXISTS idx_tasks_rank;
+ALTER TABLE tasks DROP COLUMN rank;
+ALTER TABLE tasks DROP COLUMN status;
@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER REFERENCES tasks(id) ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE,
+    owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    tasks TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS templates;
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN hidden_until DATETIME;
+CREATE INDEX IF NOT EXISTS idx_tasks_hidden_until ON tasks(hidden_until);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tasks_hidden_until;
+ALTER TABLE tasks DROP COLUMN hidden_until;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    before_due TEXT NOT NULL DEFAULT '',
+    remind_at DATETIME,
+    status TEXT NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    sent_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_reminders_task ON reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(status, remind_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS reminders;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS digest_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    frequency TEXT NOT NULL,
+    hour INTEGER NOT NULL,
+    weekday TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    last_sent_at DATETIME,
+    updated_at DATETIME NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS digest_settings;
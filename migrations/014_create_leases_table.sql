@@ -0,0 +1,9 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS leases (
+    name TEXT PRIMARY KEY,
+    owner TEXT NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS leases;